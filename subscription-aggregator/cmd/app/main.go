@@ -2,19 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	_ "subscription-aggregator/docs"
 
 	"subscription-aggregator/internal/db"
+	"subscription-aggregator/internal/export"
 	"subscription-aggregator/internal/handler"
+	"subscription-aggregator/internal/ids"
 	"subscription-aggregator/internal/repository"
+	apptime "subscription-aggregator/internal/time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X main.version=1.2.3 -X main.commit=$(git rev-parse --short HEAD)" ./cmd/app
+//
+// APP_VERSION and COMMIT_SHA override them at runtime.
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+const (
+	defaultStartupWindow = 30 * time.Second
+	defaultDrainDelay    = 5 * time.Second
+	shutdownTimeout      = 30 * time.Second
+)
+
+// @title        Subscription Aggregator API
+// @version      1.0
+// @description  REST API for managing and aggregating user subscriptions.
+// @host         localhost:8080
+// @BasePath     /
+// @schemes      http
 func main() {
 	logLevel := slog.LevelInfo
 	if os.Getenv("LOG_LEVEL") == "debug" {
@@ -25,45 +56,271 @@ func main() {
 	}))
 	slog.SetDefault(logger)
 
+	startupWindow := defaultStartupWindow
+	if v := os.Getenv("STARTUP_DELAY_SECONDS"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid STARTUP_DELAY_SECONDS, using default", "value", v)
+		} else {
+			startupWindow = time.Duration(secs) * time.Second
+		}
+	}
+	health := handler.NewHealth(apptime.RealClock{}, startupWindow)
+
+	// Serve probes while the database is initialised and migrated so that
+	// slow migrations do not fail liveness checks; API routes are added later.
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health/live", health.Live)
+	mux.HandleFunc("GET /readyz", health.Readyz)
+	serverVersion, serverCommit := envOr("APP_VERSION", version), envOr("COMMIT_SHA", commit)
+	mux.HandleFunc("GET /version", handler.Version(serverVersion, serverCommit))
+
+	port := os.Getenv("SERVER_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	maxConcurrent := handler.DefaultMaxConcurrentRequests
+	if v := os.Getenv("MAX_CONCURRENT_REQUESTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			slog.Warn("Invalid MAX_CONCURRENT_REQUESTS, using default", "value", v)
+		} else {
+			maxConcurrent = n
+		}
+	}
+
+	gzipMinBytes := handler.DefaultGzipMinBytes
+	if v := os.Getenv("GZIP_MIN_BYTES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			slog.Warn("Invalid GZIP_MIN_BYTES, using default", "value", v)
+		} else {
+			gzipMinBytes = n
+		}
+	}
+	// GzipMiddleware owns the level range check; gzipMinBytes is valid here.
+	gzipMiddleware, _ := handler.GzipMiddleware(handler.DefaultGzipLevel, gzipMinBytes)
+	if v := os.Getenv("GZIP_LEVEL"); v != "" {
+		level, err := strconv.Atoi(v)
+		var mw handler.Middleware
+		if err == nil {
+			mw, err = handler.GzipMiddleware(level, gzipMinBytes)
+		}
+		if err != nil {
+			slog.Warn("Invalid GZIP_LEVEL, using default", "value", v, "error", err)
+		} else {
+			gzipMiddleware = mw
+		}
+	}
+
+	// The envelope changes every response body, so existing clients keep
+	// the bare shape unless it is switched on.
+	var app http.Handler = mux
+	if v := os.Getenv("RESPONSE_ENVELOPE"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid RESPONSE_ENVELOPE, leaving the envelope off", "value", v)
+		} else if enabled {
+			app = handler.EnvelopeMiddleware(serverVersion, serverCommit, apptime.RealClock{})(mux)
+		}
+	}
+
+	middleware := handler.ServerMiddleware(os.Getenv("HTTPS_ONLY") == "true", health, maxConcurrent, gzipMiddleware)
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: handler.Chain(app, middleware...),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		slog.Info("🚀 Starting HTTP server", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("❌ Server crashed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
 	if err := db.InitDB(); err != nil {
 		slog.Error("❌ Failed to initialize database", "error", err)
 		os.Exit(1)
 	}
-	defer func() {
-		if err := db.GetConn().Close(context.Background()); err != nil {
-			slog.Warn("Failed to close DB connection", "error", err)
-		}
-	}()
+	defer db.GetConn().Close()
+
+	if replica := db.GetReplicaConn(); replica != nil {
+		defer replica.Close()
+	}
 
 	if err := db.RunMigrations(); err != nil {
 		slog.Error("❌ Failed to run migrations", "error", err)
 		os.Exit(1)
 	}
 
-	repo := repository.NewPostgresSubscriptionRepo(db.GetConn())
-	h := handler.NewSubscriptionHandler(repo)
+	if err := db.ValidateSchema(context.Background()); err != nil {
+		slog.Error("❌ Database schema check failed", "error", err)
+		os.Exit(1)
+	}
+
+	slowQueryThreshold := repository.DefaultSlowQueryThreshold
+	if v := os.Getenv("SLOW_QUERY_MS"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			slog.Warn("Invalid SLOW_QUERY_MS, using default", "value", v)
+		} else {
+			slowQueryThreshold = time.Duration(ms) * time.Millisecond
+		}
+	}
+	primary := repository.NewSlowQueryConn(db.GetConn(), slowQueryThreshold)
 
-	mux := http.NewServeMux()
+	// DB_QUERY_TIMEOUT_SECONDS=0 disables the per-call bound.
+	queryTimeout := repository.DefaultQueryTimeout
+	if v := os.Getenv("DB_QUERY_TIMEOUT_SECONDS"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid DB_QUERY_TIMEOUT_SECONDS, using default", "value", v)
+		} else {
+			queryTimeout = time.Duration(secs) * time.Second
+		}
+	}
+
+	idScheme := ids.DefaultScheme
+	if v := os.Getenv("ID_SCHEME"); v != "" {
+		scheme, err := ids.ParseScheme(v)
+		if err != nil {
+			slog.Warn("Invalid ID_SCHEME, using default", "value", v)
+		} else {
+			idScheme = scheme
+		}
+	}
+
+	repoOpts := []repository.RepoOption{repository.WithQueryTimeout(queryTimeout), repository.WithIDScheme(idScheme)}
+	if v := os.Getenv("BULK_INSERT_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			slog.Warn("Invalid BULK_INSERT_THRESHOLD, using default", "value", v)
+		} else {
+			repoOpts = append(repoOpts, repository.WithBulkInsertThreshold(n))
+		}
+	}
+
+	repo := repository.NewPostgresSubscriptionRepo(primary, repoOpts...)
+	if replica := db.GetReplicaConn(); replica != nil {
+		repo = repository.NewPostgresSubscriptionRepoWithReplica(primary,
+			repository.NewSlowQueryConn(replica, slowQueryThreshold), repoOpts...)
+	}
+	opts := []handler.Option{handler.WithIDScheme(idScheme)}
+	if v := os.Getenv("ANOMALY_MULTIPLIER"); v != "" {
+		multiplier, err := strconv.ParseFloat(v, 64)
+		if err != nil || multiplier <= 0 {
+			slog.Warn("Invalid ANOMALY_MULTIPLIER, using default", "value", v)
+		} else {
+			opts = append(opts, handler.WithAnomalyMultiplier(multiplier))
+		}
+	}
 
-	mux.HandleFunc("POST /subscriptions", h.CreateSubscription)
-	mux.HandleFunc("GET /subscriptions/{id}", h.GetSubscription)
-	mux.HandleFunc("GET /subscriptions", h.ListSubscriptions)
-	mux.HandleFunc("PUT /subscriptions/{id}", h.UpdateSubscription)
-	mux.HandleFunc("DELETE /subscriptions/{id}", h.DeleteSubscription)
-	mux.HandleFunc("GET /subscriptions/total-cost", h.GetTotalCost)
+	if os.Getenv("STRICT_QUERY_PARAMS") == "true" {
+		opts = append(opts, handler.WithStrictQueryParams(true))
+	}
+
+	if v := os.Getenv("IMPORT_MAX_BYTES"); v != "" {
+		maxBytes, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || maxBytes <= 0 {
+			slog.Warn("Invalid IMPORT_MAX_BYTES, using default", "value", v)
+		} else {
+			opts = append(opts, handler.WithImportMaxBytes(maxBytes))
+		}
+	}
+
+	if v := os.Getenv("IMPORT_MAX_JOBS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			slog.Warn("Invalid IMPORT_MAX_JOBS, using default", "value", v)
+		} else {
+			opts = append(opts, handler.WithMaxImportJobs(n))
+		}
+	}
+
+	if v := os.Getenv("MAX_TOTALCOST_MONTHS"); v != "" {
+		months, err := strconv.Atoi(v)
+		if err != nil || months <= 0 {
+			slog.Warn("Invalid MAX_TOTALCOST_MONTHS, using default", "value", v)
+		} else {
+			opts = append(opts, handler.WithMaxTotalCostMonths(months))
+		}
+	}
+
+	if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+		opts = append(opts, handler.WithAdminToken(token))
+	}
+
+	if secret := os.Getenv("SHARE_LINK_SECRET"); secret != "" {
+		baseURL := os.Getenv("SHARE_BASE_URL")
+		if baseURL == "" {
+			baseURL = "http://localhost:" + port
+		}
+		opts = append(opts, handler.WithShareLinks(
+			repository.NewPostgresShareLinkRepo(primary, repoOpts...), []byte(secret), baseURL))
+	}
+
+	if creds := os.Getenv("GOOGLE_SERVICE_ACCOUNT_JSON"); creds != "" {
+		exporter, err := export.NewGoogleSheetsExporter(context.Background(), []byte(creds))
+		if err != nil {
+			slog.Warn("Invalid GOOGLE_SERVICE_ACCOUNT_JSON, google sheets export disabled", "error", err)
+		} else {
+			opts = append(opts, handler.WithSheetsExporter(exporter))
+		}
+	}
+
+	opts = append(opts,
+		handler.WithTemplates(repository.NewPostgresTemplateRepo(primary, repoOpts...)),
+		handler.WithUsers(repository.NewPostgresUserRepo(primary, repoOpts...)))
+
+	h := handler.NewSubscriptionHandler(repo, opts...)
+	h.RegisterRoutes(mux)
+
+	mux.Handle("GET /metrics", promhttp.Handler())
 
 	mux.Handle("/swagger/", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"),
 	))
 
-	port := os.Getenv("SERVER_PORT")
-	if port == "" {
-		port = "8080"
+	health.MarkStarted()
+	slog.Info("✅ Startup complete")
+
+	<-ctx.Done()
+	stop()
+
+	// Fail readiness first and give the load balancer time to deregister
+	// before the listener closes.
+	health.SetDraining(true)
+	drainDelay := defaultDrainDelay
+	if v := os.Getenv("DRAIN_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			drainDelay = d
+		} else {
+			slog.Warn("Invalid DRAIN_DELAY, using default", "value", v)
+		}
 	}
+	slog.Info("Draining before shutdown", "delay", drainDelay)
+	time.Sleep(drainDelay)
 
-	slog.Info("🚀 Starting HTTP server", "port", port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		slog.Error("❌ Server crashed", "error", err)
-		os.Exit(1)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Graceful shutdown failed", "error", err)
+	}
+	// Async imports run outside any request, so Shutdown does not wait for them.
+	if err := h.DrainImports(shutdownCtx); err != nil {
+		slog.Error("Cancelled unfinished import jobs", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return def
 }
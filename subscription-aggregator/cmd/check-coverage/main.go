@@ -0,0 +1,193 @@
+// Command check-coverage reports repository interface methods whose
+// implementation tests do not exercise. It parses the interface declaration
+// with go/ast, finds the implementing methods in the package sources and
+// reads their statement coverage from a profile written by
+// go test -coverprofile, exiting non-zero when any method falls below the
+// threshold.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/cover"
+)
+
+func main() {
+	ifaceFile := flag.String("iface", "internal/repository/subscription.go", "file declaring the interface")
+	ifaceName := flag.String("name", "SubscriptionRepository", "interface name")
+	implName := flag.String("impl", "PostgresSubscriptionRepo", "type implementing the interface")
+	pkgDir := flag.String("pkg", "internal/repository", "directory of the implementing package")
+	profile := flag.String("profile", "coverage.out", "coverage profile written by go test -coverprofile")
+	minPct := flag.Float64("min", 0, "minimum statement coverage percent per method; 0 only requires some coverage")
+	flag.Parse()
+
+	methods, err := interfaceMethods(*ifaceFile, *ifaceName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check-coverage:", err)
+		os.Exit(2)
+	}
+
+	funcs, err := methodDecls(*pkgDir, *implName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check-coverage:", err)
+		os.Exit(2)
+	}
+
+	profiles, err := cover.ParseProfiles(*profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check-coverage:", err)
+		os.Exit(2)
+	}
+
+	untested := undercovered(methods, funcs, profiles, *minPct)
+	if len(untested) > 0 {
+		fmt.Printf("%s: %d of %d methods below %.1f%% coverage:\n", *ifaceName, len(untested), len(methods), *minPct)
+		for _, m := range untested {
+			fmt.Println("  -", m)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("%s: all %d methods are covered by tests\n", *ifaceName, len(methods))
+}
+
+func interfaceMethods(path, name string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []string
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return true
+		}
+		iface, ok := spec.Type.(*ast.InterfaceType)
+		if !ok {
+			return false
+		}
+		found = true
+		for _, field := range iface.Methods.List {
+			if _, ok := field.Type.(*ast.FuncType); !ok {
+				continue
+			}
+			for _, ident := range field.Names {
+				methods = append(methods, ident.Name)
+			}
+		}
+		return false
+	})
+	if !found {
+		return nil, fmt.Errorf("interface %s not found in %s", name, path)
+	}
+
+	sort.Strings(methods)
+	return methods, nil
+}
+
+// funcExtent is the source range of a function declaration.
+type funcExtent struct {
+	file       string
+	start, end token.Position
+}
+
+// methodDecls finds the methods declared on recv, or on *recv, in the
+// non-test Go files of dir.
+func methodDecls(dir, recv string) (map[string]funcExtent, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	decls := make(map[string]funcExtent)
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range file.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || receiverName(fn.Recv.List[0].Type) != recv {
+				continue
+			}
+			decls[fn.Name.Name] = funcExtent{
+				file:  filepath.ToSlash(path),
+				start: fset.Position(fn.Pos()),
+				end:   fset.Position(fn.End()),
+			}
+		}
+	}
+	if len(decls) == 0 {
+		return nil, fmt.Errorf("no methods on %s found in %s", recv, dir)
+	}
+	return decls, nil
+}
+
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// undercovered lists the interface methods whose implementation is missing
+// or has no more than minPct percent of its statements covered.
+func undercovered(methods []string, funcs map[string]funcExtent, profiles []*cover.Profile, minPct float64) []string {
+	var untested []string
+	for _, m := range methods {
+		fn, ok := funcs[m]
+		if !ok {
+			untested = append(untested, m)
+			continue
+		}
+		if pct := coverage(fn, profiles); pct == 0 || pct < minPct {
+			untested = append(untested, m)
+		}
+	}
+	return untested
+}
+
+// coverage returns the percentage of fn's statements that ran, the same
+// figure go tool cover -func reports.
+func coverage(fn funcExtent, profiles []*cover.Profile) float64 {
+	var total, covered int
+	for _, p := range profiles {
+		// Profiles name files by import path, so match on the local path suffix.
+		if p.FileName != fn.file && !strings.HasSuffix(p.FileName, "/"+fn.file) {
+			continue
+		}
+		for _, b := range p.Blocks {
+			if before(b.StartLine, b.StartCol, fn.start) || before(fn.end.Line, fn.end.Column, token.Position{Line: b.EndLine, Column: b.EndCol}) {
+				continue
+			}
+			total += b.NumStmt
+			if b.Count > 0 {
+				covered += b.NumStmt
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
+
+func before(line, col int, pos token.Position) bool {
+	return line < pos.Line || line == pos.Line && col < pos.Column
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/cover"
+)
+
+const fixtureSource = `package repo
+
+type Repo interface {
+	Get() int
+	Put(int)
+	Delete()
+}
+
+type impl struct{}
+
+func (r *impl) Get() int {
+	return 1
+}
+
+func (r *impl) Put(n int) {
+	_ = n
+	_ = n
+}
+`
+
+func writeFixture(t *testing.T, profile string) (dir string, profiles []*cover.Profile) {
+	t.Helper()
+	dir = filepath.ToSlash(t.TempDir())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "repo.go"), []byte(fixtureSource), 0o644))
+	profilePath := filepath.Join(dir, "coverage.out")
+	profile = strings.ReplaceAll(profile, "FILE", dir+"/repo.go")
+	require.NoError(t, os.WriteFile(profilePath, []byte(profile), 0o644))
+	profiles, err := cover.ParseProfiles(profilePath)
+	require.NoError(t, err)
+	return dir, profiles
+}
+
+func TestUndercovered(t *testing.T) {
+	dir, profiles := writeFixture(t, "mode: set\n"+
+		"FILE:11.26,13.2 1 1\n"+
+		"FILE:15.27,18.2 1 1\n"+
+		"FILE:17.2,18.2 1 0\n")
+
+	methods, err := interfaceMethods(filepath.Join(dir, "repo.go"), "Repo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Delete", "Get", "Put"}, methods)
+
+	funcs, err := methodDecls(dir, "impl")
+	require.NoError(t, err)
+	assert.Len(t, funcs, 2)
+	assert.Equal(t, 100.0, coverage(funcs["Get"], profiles))
+	assert.Equal(t, 50.0, coverage(funcs["Put"], profiles))
+
+	assert.Equal(t, []string{"Delete"}, undercovered(methods, funcs, profiles, 0),
+		"a method with no implementation is never covered")
+	assert.Equal(t, []string{"Delete", "Put"}, undercovered(methods, funcs, profiles, 75))
+}
+
+func TestUndercovered_NoCoverage(t *testing.T) {
+	dir, profiles := writeFixture(t, "mode: set\n"+
+		"FILE:11.26,13.2 1 0\n"+
+		"FILE:15.27,18.2 2 1\n")
+
+	funcs, err := methodDecls(dir, "impl")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Get"}, undercovered([]string{"Get", "Put"}, funcs, profiles, 0))
+}
+
+func TestInterfaceMethods_NotFound(t *testing.T) {
+	dir, _ := writeFixture(t, "mode: set\n")
+	_, err := interfaceMethods(filepath.Join(dir, "repo.go"), "Missing")
+	assert.Error(t, err)
+	_, err = methodDecls(dir, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+// Command check-dates reports start_date and end_date values that are not in
+// MM-YYYY format, so a conversion of those columns to DATE does not fail half
+// way. With -fix it rewrites repairable values and quarantines the remaining
+// rows in subscriptions_date_quarantine. It exits non-zero while bad values remain.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"subscription-aggregator/internal/db"
+	"subscription-aggregator/internal/ids"
+)
+
+func main() {
+	fix := flag.Bool("fix", false, "repair or quarantine malformed dates")
+	flag.Parse()
+
+	err := db.InitDB()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check-dates:", err)
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	conn := db.GetConn()
+	defer conn.Close()
+
+	scheme := ids.DefaultScheme
+	if v := os.Getenv("ID_SCHEME"); v != "" {
+		if scheme, err = ids.ParseScheme(v); err != nil {
+			fmt.Fprintln(os.Stderr, "check-dates:", err)
+			os.Exit(2)
+		}
+	}
+
+	found, err := db.FindMalformedDates(ctx, conn, scheme)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check-dates:", err)
+		os.Exit(2)
+	}
+	if len(found) == 0 {
+		fmt.Println("check-dates: all subscription dates are MM-YYYY")
+		return
+	}
+
+	for _, d := range found {
+		suggestion := "quarantine"
+		if v, ok := db.NormalizeMonthYear(d.Value); ok {
+			suggestion = "fix to " + v
+		}
+		fmt.Printf("%s\t%s\t%q\t%s\n", d.ID, d.Column, d.Value, suggestion)
+	}
+
+	if !*fix {
+		fmt.Fprintf(os.Stderr, "check-dates: %d malformed values, rerun with -fix to repair\n", len(found))
+		os.Exit(1)
+	}
+
+	fixed, quarantined, err := db.RepairMalformedDates(ctx, conn, scheme, found)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "check-dates:", err)
+		os.Exit(2)
+	}
+	fmt.Printf("check-dates: fixed %d values, quarantined %d rows\n", fixed, quarantined)
+}
@@ -0,0 +1,43 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListActiveAt(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	for _, s := range []map[string]interface{}{
+		{"service_name": "Spotify", "start_date": "01-2024"},
+		{"service_name": "Netflix", "start_date": "02-2024", "end_date": "05-2024"},
+		{"service_name": "Kion", "start_date": "01-2024", "end_date": "04-2024"},
+		{"service_name": "Yandex Plus", "start_date": "06-2024"},
+	} {
+		s["price"] = 300
+		s["user_id"] = userID
+		createSubscription(t, server.URL, s)
+	}
+
+	resp, err := http.Get(server.URL + "/subscriptions/active-at?user_id=" + userID + "&month=05-2024")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page model.PaginatedResponse[model.Subscription]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	var names []string
+	for _, s := range page.Data {
+		names = append(names, s.ServiceName)
+	}
+	assert.Equal(t, []string{"Netflix", "Spotify"}, names)
+	assert.Equal(t, 2, page.Pagination.Total)
+}
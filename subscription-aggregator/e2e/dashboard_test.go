@@ -0,0 +1,45 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserDashboard(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": userID, "start_date": "02-2026"})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Kinopoisk", "price": 250, "user_id": userID, "start_date": "12-2024", "end_date": "12-2024"})
+
+	resp, err := http.Get(server.URL + "/users/" + userID + "/dashboard?from=01-2025&to=12-2025")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var dashboard model.UserDashboard
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&dashboard))
+	require.Len(t, dashboard.Subscriptions, 3, "the list is not limited to the period")
+	var order []string
+	for _, sub := range dashboard.Subscriptions {
+		order = append(order, sub.ServiceName)
+	}
+	assert.Equal(t, []string{"Netflix", "Spotify", "Kinopoisk"}, order, "newest start_date first, by month not text")
+	assert.Equal(t, model.CostSummary{Total: 300, Count: 1, Matched: true}, dashboard.TotalCost)
+
+	resp2, err := http.Get(server.URL + "/users/" + userID + "/dashboard?from=01-2025")
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp2.StatusCode)
+}
@@ -0,0 +1,49 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateCheck(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	spotifyID := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": userID, "start_date": "01-2025"})
+
+	body := map[string]interface{}{
+		"service_name": "spotify", "price": 350, "user_id": userID, "start_date": "06-2025"}
+	resp, err := http.Post(server.URL+"/subscriptions/"+uuid.New().String()+"/duplicate-check", "application/json", jsonBody(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Duplicates []struct {
+			ID         string  `json:"id"`
+			Similarity float64 `json:"similarity"`
+			Reason     string  `json:"reason"`
+		} `json:"duplicates"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Len(t, result.Duplicates, 1)
+	assert.Equal(t, spotifyID, result.Duplicates[0].ID)
+	assert.Equal(t, 1.0, result.Duplicates[0].Similarity)
+	assert.Equal(t, "same_service_overlapping_dates", result.Duplicates[0].Reason)
+
+	resp, err = http.Post(server.URL+"/subscriptions/"+spotifyID+"/duplicate-check", "application/json", jsonBody(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Empty(t, result.Duplicates, "the subscription being edited must be excluded")
+}
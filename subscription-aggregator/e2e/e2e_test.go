@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,6 +14,9 @@ import (
 	"subscription-aggregator/internal/handler"
 	"subscription-aggregator/internal/repository"
 
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -20,47 +24,58 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestEndToEnd(t *testing.T) {
-	db, err := sql.Open("pgx", "host=localhost port=5433 user=testuser password=testpass dbname=testdb sslmode=disable")
+const testDSN = "host=localhost port=5433 user=testuser password=testpass dbname=testdb sslmode=disable"
+
+type testEnv struct {
+	db     *sql.DB
+	conn   *pgx.Conn
+	server *httptest.Server
+}
+
+func setupTestEnv(t *testing.T, opts ...handler.Option) *testEnv {
+	t.Helper()
+
+	db, err := sql.Open("pgx", testDSN)
 	require.NoError(t, err)
-	defer db.Close()
+	t.Cleanup(func() { db.Close() })
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	require.NoError(t, db.PingContext(ctx))
 
-	_, err = db.ExecContext(ctx, `
-		CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-		CREATE TABLE subscriptions (
-			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			service_name TEXT NOT NULL,
-			price INTEGER NOT NULL CHECK (price > 0),
-			user_id UUID NOT NULL,
-			start_date TEXT NOT NULL,
-			end_date TEXT
-		);
-	`)
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	require.NoError(t, err)
+	m, err := migrate.NewWithDatabaseInstance("file://../migrations", "postgres", driver)
 	require.NoError(t, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(t, err)
+	}
 
-	pgxConn, err := pgx.Connect(ctx, "host=localhost port=5433 user=testuser password=testpass dbname=testdb sslmode=disable")
+	pgxConn, err := pgx.Connect(ctx, testDSN)
 	require.NoError(t, err)
-	defer pgxConn.Close(ctx)
+	t.Cleanup(func() { pgxConn.Close(context.Background()) })
 
 	repo := repository.NewPostgresSubscriptionRepo(pgxConn)
-	h := handler.NewSubscriptionHandler(repo)
+	opts = append([]handler.Option{
+		handler.WithTemplates(repository.NewPostgresTemplateRepo(pgxConn)),
+		handler.WithUsers(repository.NewPostgresUserRepo(pgxConn)),
+	}, opts...)
+	h := handler.NewSubscriptionHandler(repo, opts...)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("POST /subscriptions", h.CreateSubscription)
-	mux.HandleFunc("GET /subscriptions/{id}", h.GetSubscription)
-	mux.HandleFunc("GET /subscriptions", h.ListSubscriptions)
-	mux.HandleFunc("PUT /subscriptions/{id}", h.UpdateSubscription)
-	mux.HandleFunc("DELETE /subscriptions/{id}", h.DeleteSubscription)
-	mux.HandleFunc("GET /subscriptions/total-cost", h.GetTotalCost)
+	h.RegisterRoutes(mux)
 
 	server := httptest.NewServer(mux)
-	defer server.Close()
+	t.Cleanup(server.Close)
 
-	userID := uuid.New().String()
+	return &testEnv{db: db, conn: pgxConn, server: server}
+}
+
+func TestEndToEnd(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
 	t.Run("Create subscription", func(t *testing.T) {
 		body := map[string]interface{}{
 			"service_name": "Yandex Plus", "price": 400,
@@ -73,11 +88,127 @@ func TestEndToEnd(t *testing.T) {
 		var created map[string]interface{}
 		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
 		assert.NotEmpty(t, created["id"])
+		for _, field := range []string{"created_at", "updated_at"} {
+			_, err := time.Parse(time.RFC3339, created[field].(string))
+			assert.NoError(t, err, field)
+		}
+	})
+
+	subID := uuid.New().String()
+	t.Run("Create subscription via PUT", func(t *testing.T) {
+		body := map[string]interface{}{
+			"service_name": "Kinopoisk", "price": 300,
+			"user_id": userID, "start_date": "08-2025"}
+		resp := doPut(t, server.URL+"/subscriptions/"+subID, body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var created map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, subID, created["id"])
+		assert.Equal(t, float64(300), created["price"])
+	})
+
+	t.Run("Update subscription via PUT", func(t *testing.T) {
+		body := map[string]interface{}{
+			"service_name": "Kinopoisk", "price": 350,
+			"user_id": userID, "start_date": "08-2025"}
+		resp := doPut(t, server.URL+"/subscriptions/"+subID, body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var updated map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+		assert.Equal(t, subID, updated["id"])
+		assert.Equal(t, float64(350), updated["price"])
+	})
+
+	t.Run("PUT with another user_id", func(t *testing.T) {
+		body := map[string]interface{}{
+			"service_name": "Kinopoisk", "price": 350,
+			"user_id": createUser(t, server.URL), "start_date": "08-2025"}
+		resp := doPut(t, server.URL+"/subscriptions/"+subID, body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode, "PUT must not move a subscription to another user")
+
+		get, err := http.Get(server.URL + "/subscriptions/" + subID)
+		require.NoError(t, err)
+		defer get.Body.Close()
+		var stored map[string]interface{}
+		require.NoError(t, json.NewDecoder(get.Body).Decode(&stored))
+		assert.Equal(t, userID, stored["user_id"])
+		assert.Equal(t, float64(350), stored["price"])
+	})
+
+	t.Run("PUT to a deleted ID", func(t *testing.T) {
+		deletedID := createSubscription(t, server.URL, map[string]interface{}{
+			"service_name": "Okko", "price": 200, "user_id": userID, "start_date": "09-2025"})
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/subscriptions/"+deletedID, nil)
+		require.NoError(t, err)
+		del, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		del.Body.Close()
+		require.Equal(t, http.StatusNoContent, del.StatusCode)
+
+		body := map[string]interface{}{
+			"service_name": "Okko", "price": 250,
+			"user_id": userID, "start_date": "09-2025"}
+		resp := doPut(t, server.URL+"/subscriptions/"+deletedID, body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode, "PUT must not bring back a deleted subscription")
+
+		get, err := http.Get(server.URL + "/subscriptions/" + deletedID)
+		require.NoError(t, err)
+		get.Body.Close()
+		assert.Equal(t, http.StatusNotFound, get.StatusCode, "the subscription stays deleted")
+	})
+
+	t.Run("PUT with invalid ID", func(t *testing.T) {
+		body := map[string]interface{}{
+			"service_name": "Kinopoisk", "price": 350,
+			"user_id": userID, "start_date": "08-2025"}
+		resp := doPut(t, server.URL+"/subscriptions/not-a-uuid", body)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
 	t.Log("✅ Тест пройден")
 }
 
+// createUser registers a fresh user, which must exist before any of its
+// subscriptions can be created.
+func createUser(t *testing.T, baseURL string) string {
+	t.Helper()
+	id := uuid.New().String()
+	resp, err := http.Post(baseURL+"/users", "application/json", jsonBody(map[string]string{"id": id}))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	return id
+}
+
+func createSubscription(t *testing.T, baseURL string, body interface{}) string {
+	t.Helper()
+	resp, err := http.Post(baseURL+"/subscriptions", "application/json", jsonBody(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var created map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	return created["id"].(string)
+}
+
+func doPut(t *testing.T, url string, body interface{}) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, url, jsonBody(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
 func jsonBody(v interface{}) *bytes.Reader {
 	data, _ := json.Marshal(v)
 	return bytes.NewReader(data)
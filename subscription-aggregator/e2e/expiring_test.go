@@ -0,0 +1,46 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/handler"
+	"subscription-aggregator/internal/model"
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiringSoon_EndToEnd(t *testing.T) {
+	clock := apptime.NewFakeClock(time.Date(2030, time.January, 15, 12, 0, 0, 0, time.UTC))
+	env := setupTestEnv(t, handler.WithClock(clock))
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	for _, s := range []struct{ name, end string }{
+		{"One Month", "02-2030"},
+		{"Two Months", "03-2030"},
+		{"Six Months", "07-2030"},
+	} {
+		createSubscription(t, server.URL, map[string]interface{}{
+			"service_name": s.name, "price": 100, "user_id": userID,
+			"start_date": "01-2029", "end_date": s.end})
+	}
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Open Ended", "price": 100, "user_id": userID, "start_date": "01-2029"})
+
+	resp, err := http.Get(server.URL + "/subscriptions/expiring-soon?months=3&user_id=" + userID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page model.PaginatedResponse[model.Subscription]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	require.Len(t, page.Data, 2)
+	assert.Equal(t, "One Month", page.Data[0].ServiceName)
+	assert.Equal(t, "Two Months", page.Data[1].ServiceName)
+	assert.Equal(t, 2, page.Pagination.Total)
+}
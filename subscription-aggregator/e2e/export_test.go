@@ -0,0 +1,91 @@
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUserData(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+	netflixID := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": userID, "start_date": "02-2025"})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": createUser(t, server.URL), "start_date": "02-2025"})
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/subscriptions/"+netflixID, nil)
+	require.NoError(t, err)
+	del, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	del.Body.Close()
+	require.Equal(t, http.StatusNoContent, del.StatusCode)
+
+	resp, err := http.Get(server.URL + "/users/" + userID + "/export")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="export-`+userID+`.zip"`, resp.Header.Get("Content-Disposition"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "subscriptions.json", zr.File[0].Name)
+
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer f.Close()
+
+	var subs []model.Subscription
+	require.NoError(t, json.NewDecoder(f).Decode(&subs))
+	assert.Len(t, subs, 2)
+	for _, s := range subs {
+		assert.Equal(t, userID, s.UserID)
+		if s.ID == netflixID {
+			assert.NotNil(t, s.DeletedAt, "soft-deleted subscriptions are exported")
+		} else {
+			assert.Nil(t, s.DeletedAt)
+		}
+	}
+}
+
+func TestExportSubscriptions_Terraform(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Yandex Plus", "price": 400, "user_id": userID, "start_date": "01-2025"})
+
+	resp, err := http.Get(server.URL + "/subscriptions/export?format=terraform&user_id=" + userID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var state model.TerraformState
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&state))
+	require.Len(t, state.Resources, 1)
+	assert.Equal(t, "subscription", state.Resources[0].Type)
+	assert.Equal(t, "yandex_plus", state.Resources[0].Name)
+	assert.Equal(t, 400, state.Resources[0].Values.Price)
+
+	resp2, err := http.Get(server.URL + "/subscriptions/export?format=yaml&user_id=" + userID)
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp2.StatusCode)
+}
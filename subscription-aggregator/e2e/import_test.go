@@ -0,0 +1,51 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportUpsert(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	id := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+
+	resp, err := http.Post(server.URL+"/subscriptions", "application/json", jsonBody(map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"}))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	csv := "service_name,price,user_id,start_date,end_date\n" +
+		"Spotify,350," + userID + ",01-2025,12-2025\n" +
+		"Netflix,800," + userID + ",01-2025,\n"
+	resp, err = http.Post(server.URL+"/subscriptions/import?upsert=true", "text/csv", strings.NewReader(csv))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result model.ImportResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	assert.Zero(t, result.Failed)
+
+	resp, err = http.Get(server.URL + "/subscriptions/" + id)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var sub model.Subscription
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sub))
+	assert.Equal(t, 350, sub.Price)
+	require.NotNil(t, sub.EndDate)
+	assert.Equal(t, "12-2025", sub.EndDate.String())
+}
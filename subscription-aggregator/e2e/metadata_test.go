@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionMetadata(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	id := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025",
+		"metadata": map[string]interface{}{"external_id": "ext-42", "seats": 3}})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": userID, "start_date": "01-2025",
+		"metadata": map[string]interface{}{"external_id": "ext-7"}})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Yandex Plus", "price": 400, "user_id": userID, "start_date": "01-2025"})
+
+	resp, err := http.Get(server.URL + "/subscriptions/" + id)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var sub model.Subscription
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sub))
+	assert.JSONEq(t, `{"external_id": "ext-42", "seats": 3}`, string(sub.Metadata))
+
+	list := func(query string) []model.Subscription {
+		resp, err := http.Get(server.URL + "/subscriptions?user_id=" + userID + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page model.PaginatedResponse[model.Subscription]
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page.Data
+	}
+
+	matched := list("&meta.external_id=ext-42")
+	require.Len(t, matched, 1)
+	assert.Equal(t, id, matched[0].ID)
+	assert.Len(t, list("&meta.seats=3"), 1)
+	assert.Empty(t, list("&meta.external_id=missing"))
+	assert.Len(t, list(""), 3)
+
+	resp2, err := http.Post(server.URL+"/subscriptions", "application/json", jsonBody(map[string]interface{}{
+		"service_name": "Kion", "price": 200, "user_id": userID, "start_date": "01-2025", "metadata": []int{1}}))
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp2.StatusCode)
+}
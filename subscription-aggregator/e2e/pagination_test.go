@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorPagination(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	created := make(map[string]bool)
+	for i := range 50 {
+		id := createSubscription(t, server.URL, map[string]interface{}{
+			"service_name": "Paged " + strconv.Itoa(i), "price": 100, "user_id": userID,
+			"start_date": fmt.Sprintf("%02d-%d", i%12+1, 2020+i%5)})
+		created[id] = true
+	}
+
+	seen := make(map[string]bool)
+	var prev model.Subscription
+	after := ""
+	pages := 0
+	for {
+		require.Less(t, pages, 6, "pagination did not terminate")
+		resp, err := http.Get(server.URL + "/subscriptions?user_id=" + userID + "&limit=10&after=" + url.QueryEscape(after))
+		require.NoError(t, err)
+
+		var page model.CursorPage[model.Subscription]
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		resp.Body.Close()
+		pages++
+
+		for _, s := range page.Data {
+			assert.False(t, seen[s.ID], "subscription %s returned twice", s.ID)
+			assert.False(t, s.StartDate.Before(prev.StartDate), "pages must be in start_date order")
+			seen[s.ID] = true
+			prev = s
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		assert.Len(t, page.Data, 10)
+		after = page.NextCursor
+	}
+
+	assert.Equal(t, 5, pages)
+	assert.Equal(t, created, seen)
+}
@@ -0,0 +1,61 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchSubscription(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	id := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Yandex Plus", "price": 400, "user_id": userID, "start_date": "03-2025",
+		"metadata": map[string]string{"plan": "family"}})
+
+	patch := func(body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPatch, server.URL+"/subscriptions/"+id, jsonBody(json.RawMessage(body)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := patch(`{"price": 450}`)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var patched map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&patched))
+	assert.Equal(t, float64(450), patched["price"])
+	assert.Equal(t, "Yandex Plus", patched["service_name"])
+	assert.Equal(t, "03-2025", patched["start_date"])
+	assert.Equal(t, map[string]interface{}{"plan": "family"}, patched["metadata"])
+	assert.NotContains(t, patched, "end_date")
+
+	resp = patch(`{"end_date": "12-2025"}`)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	get, err := http.Get(server.URL + "/subscriptions/" + id)
+	require.NoError(t, err)
+	defer get.Body.Close()
+	var stored map[string]interface{}
+	require.NoError(t, json.NewDecoder(get.Body).Decode(&stored))
+	assert.Equal(t, float64(450), stored["price"], "earlier patch survives")
+	assert.Equal(t, "12-2025", stored["end_date"])
+
+	resp = patch(`{"price": -1}`)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = patch(`{"start_date": "01-2026"}`)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "start_date is checked against the stored end_date")
+}
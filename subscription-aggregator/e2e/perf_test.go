@@ -0,0 +1,59 @@
+package e2e
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sloSeedRows  = 1000
+	sloSeedUsers = 100
+	sloCalls     = 100
+	sloP95       = 50 * time.Millisecond
+)
+
+func TestEndToEnd_LatencySLO(t *testing.T) {
+	env := setupTestEnv(t)
+
+	userIDs := make([]string, sloSeedUsers)
+	for i := range userIDs {
+		userIDs[i] = uuid.New().String()
+	}
+
+	_, err := env.db.ExecContext(context.Background(),
+		`INSERT INTO users (id) SELECT unnest($1::uuid[])`, userIDs)
+	require.NoError(t, err)
+
+	_, err = env.db.ExecContext(context.Background(), `
+		INSERT INTO subscriptions (service_name, price, user_id, start_date)
+		SELECT 'Service ' || n, 100 + n, ($1::uuid[])[1 + n % $2], '07-2025'
+		FROM generate_series(1, $3) AS n`,
+		userIDs, sloSeedUsers, sloSeedRows)
+	require.NoError(t, err)
+
+	target := env.server.URL + "/subscriptions?user_id=" + userIDs[0]
+	durations := make([]time.Duration, 0, sloCalls)
+	for i := 0; i < sloCalls; i++ {
+		start := time.Now()
+		resp, err := http.Get(target)
+		require.NoError(t, err)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		durations = append(durations, time.Since(start))
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	p95 := durations[(len(durations)*95+99)/100-1]
+	if p95 >= sloP95 {
+		t.Fatalf("p95 latency SLO violated: p95=%s, want < %s over %d calls", p95, sloP95, sloCalls)
+	}
+	t.Logf("p95 latency: %s", p95)
+}
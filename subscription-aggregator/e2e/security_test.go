@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceNameFilter_SQLInjection(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	const payload = "'; DROP TABLE subscriptions; --"
+	userID := createUser(t, server.URL)
+
+	t.Run("List with injected service_name", func(t *testing.T) {
+		q := url.Values{"user_id": {userID}, "service_name": {payload}}
+		resp, err := http.Get(server.URL + "/subscriptions?" + q.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.NotNil(t, page.Data)
+		assert.Empty(t, page.Data)
+	})
+
+	t.Run("Total cost with injected service_name", func(t *testing.T) {
+		q := url.Values{"user_id": {userID}, "service_name": {payload}, "from": {"01-2025"}, "to": {"12-2025"}}
+		resp, err := http.Get(server.URL + "/subscriptions/total-cost?" + q.Encode())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result model.CostSummary
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, 0, result.Total)
+		assert.False(t, result.Matched)
+	})
+
+	t.Run("Create stores injected service_name inertly", func(t *testing.T) {
+		existingID := createSubscription(t, server.URL, map[string]interface{}{
+			"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "06-2025"})
+
+		body := map[string]interface{}{
+			"service_name": payload, "price": 400,
+			"user_id": userID, "start_date": "07-2025"}
+		resp, err := http.Post(server.URL+"/subscriptions", "application/json", jsonBody(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		// Quotes, semicolons and dashes are valid in service names, so the
+		// payload is not rejected with 400/422. Queries bind it as a
+		// parameter, and the checks below show it is stored as plain text
+		// without touching the table or other rows.
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var created model.Subscription
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, payload, created.ServiceName)
+
+		q := url.Values{"user_id": {userID}, "service_name": {payload}}
+		list, err := http.Get(server.URL + "/subscriptions?" + q.Encode())
+		require.NoError(t, err)
+		defer list.Body.Close()
+		var page struct {
+			Data []model.Subscription `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(list.Body).Decode(&page))
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, created.ID, page.Data[0].ID)
+
+		var table *string
+		require.NoError(t, env.db.QueryRowContext(context.Background(),
+			`SELECT to_regclass('public.subscriptions')::text`).Scan(&table))
+		require.NotNil(t, table, "subscriptions table must still exist")
+
+		get, err := http.Get(server.URL + "/subscriptions/" + existingID)
+		require.NoError(t, err)
+		defer get.Body.Close()
+		require.Equal(t, http.StatusOK, get.StatusCode, "other rows are untouched")
+		var existing model.Subscription
+		require.NoError(t, json.NewDecoder(get.Body).Decode(&existing))
+		assert.Equal(t, "Spotify", existing.ServiceName)
+		assert.Equal(t, 300, existing.Price)
+	})
+
+	var table *string
+	require.NoError(t, env.db.QueryRowContext(context.Background(),
+		`SELECT to_regclass('public.subscriptions')::text`).Scan(&table))
+	require.NotNil(t, table, "subscriptions table must still exist")
+}
@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLAIncidents(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	flaky := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025", "sla_uptime_pct": 99.9})
+	once := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": userID, "start_date": "01-2025"})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Kion", "price": 200, "user_id": userID, "start_date": "01-2025"})
+
+	incident := func(id string) model.Subscription {
+		resp, err := http.Post(server.URL+"/subscriptions/"+id+"/sla-incident", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var sub model.Subscription
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sub))
+		return sub
+	}
+	incident(flaky)
+	sub := incident(flaky)
+	assert.Equal(t, 2, sub.IncidentCount)
+	require.NotNil(t, sub.LastIncidentAt)
+	require.NotNil(t, sub.SLAUptimePct)
+	assert.Equal(t, 99.9, *sub.SLAUptimePct)
+	incident(once)
+
+	breaches := func(query string) []string {
+		resp, err := http.Get(server.URL + "/subscriptions/sla-breaches?user_id=" + userID + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page model.PaginatedResponse[model.Subscription]
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		var ids []string
+		for _, s := range page.Data {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{once, flaky}, breaches(""))
+	assert.Equal(t, []string{flaky}, breaches("&min_incidents=2"))
+
+	resp, err := http.Post(server.URL+"/subscriptions/"+uuid.New().String()+"/sla-incident", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
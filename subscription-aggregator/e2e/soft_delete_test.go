@@ -0,0 +1,58 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	id := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Yandex Music", "price": 250, "user_id": userID, "start_date": "02-2025"})
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/subscriptions/"+id, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	get, err := http.Get(server.URL + "/subscriptions/" + id)
+	require.NoError(t, err)
+	get.Body.Close()
+	assert.Equal(t, http.StatusNotFound, get.StatusCode, "deleted subscriptions are hidden")
+
+	restore := func() *http.Response {
+		resp, err := http.Post(server.URL+"/subscriptions/"+id+"/restore", "application/json", nil)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp = restore()
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var restored map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&restored))
+	assert.Equal(t, id, restored["id"])
+	assert.Equal(t, "Yandex Music", restored["service_name"])
+	assert.NotContains(t, restored, "deleted_at")
+
+	get, err = http.Get(server.URL + "/subscriptions/" + id)
+	require.NoError(t, err)
+	defer get.Body.Close()
+	var stored map[string]interface{}
+	require.NoError(t, json.NewDecoder(get.Body).Decode(&stored))
+	assert.Equal(t, http.StatusOK, get.StatusCode)
+	assert.Equal(t, float64(250), stored["price"])
+
+	resp = restore()
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "only deleted subscriptions can be restored")
+}
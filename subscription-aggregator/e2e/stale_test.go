@@ -0,0 +1,58 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListStaleSubscriptions(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+	ctx := context.Background()
+
+	userID := createUser(t, server.URL)
+	staleID := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Old Service", "price": 100, "user_id": userID, "start_date": "01-2024"})
+	neverReadID := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Forgotten", "price": 100, "user_id": userID, "start_date": "01-2024"})
+	freshID := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Fresh", "price": 100, "user_id": userID, "start_date": "01-2025"})
+
+	_, err := env.db.ExecContext(ctx, `
+		UPDATE subscriptions SET last_accessed_at = NOW() - INTERVAL '120 days' WHERE id = $1`, staleID)
+	require.NoError(t, err)
+	_, err = env.db.ExecContext(ctx, `
+		UPDATE subscriptions SET created_at = NOW() - INTERVAL '200 days', last_accessed_at = NULL WHERE id = $1`, neverReadID)
+	require.NoError(t, err)
+
+	resp, err := http.Get(server.URL + "/subscriptions/" + freshID)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/subscriptions/stale?days=90&user_id=" + userID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page struct {
+		Data       []map[string]interface{} `json:"data"`
+		Pagination map[string]interface{}   `json:"pagination"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	ids := make([]string, 0, len(page.Data))
+	for _, s := range page.Data {
+		ids = append(ids, s["id"].(string))
+	}
+	assert.ElementsMatch(t, []string{staleID, neverReadID}, ids)
+	assert.Equal(t, float64(2), page.Pagination["total"])
+
+	resp, err = http.Get(server.URL + "/subscriptions/stale?days=0&user_id=" + userID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	t.Run("search", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/templates?search=spot")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var templates []model.Template
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&templates))
+		require.NotEmpty(t, templates)
+		assert.Equal(t, "Spotify", templates[0].ServiceName)
+		assert.Equal(t, "music", templates[0].Category)
+	})
+
+	t.Run("create from template", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/subscriptions?template_id="+url.QueryEscape("Netflix"), "application/json",
+			jsonBody(map[string]interface{}{"user_id": createUser(t, server.URL), "start_date": "07-2025"}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var sub model.Subscription
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sub))
+		assert.Equal(t, "Netflix", sub.ServiceName)
+		assert.Equal(t, 799, sub.Price)
+	})
+
+	t.Run("body overrides template", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/subscriptions?template_id=Netflix", "application/json",
+			jsonBody(map[string]interface{}{"user_id": createUser(t, server.URL), "start_date": "07-2025", "price": 999}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var sub model.Subscription
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sub))
+		assert.Equal(t, "Netflix", sub.ServiceName)
+		assert.Equal(t, 999, sub.Price)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/subscriptions?template_id=Nope", "application/json",
+			jsonBody(map[string]interface{}{"user_id": createUser(t, server.URL), "start_date": "07-2025"}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
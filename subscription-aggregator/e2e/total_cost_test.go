@@ -0,0 +1,141 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTotalCost_ExcludeService(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	for _, s := range []struct {
+		name  string
+		price int
+	}{{"Spotify", 300}, {"Netflix", 800}, {"Yandex Plus", 400}} {
+		createSubscription(t, server.URL, map[string]interface{}{
+			"service_name": s.name, "price": s.price, "user_id": userID, "start_date": "01-2025"})
+	}
+
+	period := url.Values{"user_id": {userID}, "from": {"01-2025"}, "to": {"12-2025"}}
+	total := getTotalCost(t, server.URL, period, nil)
+	without := getTotalCost(t, server.URL, period, url.Values{"exclude_service": {"Netflix"}})
+	netflix := getTotalCost(t, server.URL, period, url.Values{"service_name": {"Netflix"}})
+
+	assert.Equal(t, 1500, total)
+	assert.Equal(t, 700, without)
+	assert.Equal(t, netflix, total-without)
+
+	q := url.Values{"user_id": {userID}, "from": {"01-2025"}, "to": {"12-2025"},
+		"service_name": {"Spotify"}, "exclude_service": {"Netflix"}}
+	resp, err := http.Get(server.URL + "/subscriptions/total-cost?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTotalCost_NoMatch(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+
+	period := url.Values{"user_id": {userID}, "from": {"01-2025"}, "to": {"12-2025"}}
+
+	empty := getCostSummary(t, server.URL, period, url.Values{"service_name": {"Netflix"}})
+	assert.Equal(t, model.CostSummary{Total: 0, Count: 0, Matched: false}, empty)
+
+	spotify := getCostSummary(t, server.URL, period, url.Values{"service_name": {"Spotify"}})
+	assert.Equal(t, model.CostSummary{Total: 300, Count: 1, Matched: true}, spotify)
+}
+
+func getTotalCost(t *testing.T, baseURL string, base, extra url.Values) int {
+	t.Helper()
+	return getCostSummary(t, baseURL, base, extra).Total
+}
+
+func getCostSummary(t *testing.T, baseURL string, base, extra url.Values) model.CostSummary {
+	t.Helper()
+	q := url.Values{}
+	for k, v := range base {
+		q[k] = v
+	}
+	for k, v := range extra {
+		q[k] = v
+	}
+
+	resp, err := http.Get(baseURL + "/subscriptions/total-cost?" + q.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result model.CostSummary
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
+
+func TestServiceNameCaseSensitivity(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+
+	period := url.Values{"user_id": {userID}, "from": {"01-2025"}, "to": {"12-2025"}}
+	assert.Equal(t, 300, getTotalCost(t, server.URL, period, url.Values{"service_name": {"spotify"}}))
+	assert.Equal(t, 300, getTotalCost(t, server.URL, period,
+		url.Values{"service_name": {"spotify"}, "case_sensitive": {"false"}}))
+	assert.Equal(t, 0, getTotalCost(t, server.URL, period,
+		url.Values{"service_name": {"spotify"}, "case_sensitive": {"true"}}))
+
+	resp, err := http.Get(server.URL + "/subscriptions?user_id=" + userID + "&service_name=spotify&case_sensitive=false")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var page model.PaginatedResponse[model.Subscription]
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	require.Len(t, page.Data, 1)
+	assert.Equal(t, "Spotify", page.Data[0].ServiceName)
+}
+
+func TestTotalCost_Itemize(t *testing.T) {
+	env := setupTestEnv(t)
+	server := env.server
+
+	userID := createUser(t, server.URL)
+	spotify := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+	netflix := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Netflix", "price": 800, "user_id": userID, "start_date": "03-2025", "end_date": "06-2025"})
+	createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Yandex Plus", "price": 400, "user_id": userID, "start_date": "01-2026"})
+
+	period := url.Values{"user_id": {userID}, "from": {"01-2025"}, "to": {"12-2025"}}
+	summary := getCostSummary(t, server.URL, period, url.Values{"itemize": {"true"}})
+
+	require.Len(t, summary.Items, 2)
+	assert.Equal(t, []model.CostItem{
+		{ID: netflix, ServiceName: "Netflix", Contribution: 800},
+		{ID: spotify, ServiceName: "Spotify", Contribution: 300},
+	}, summary.Items)
+
+	sum := 0
+	for _, item := range summary.Items {
+		sum += item.Contribution
+	}
+	assert.Equal(t, summary.Total, sum)
+	assert.Equal(t, getTotalCost(t, server.URL, period, nil), summary.Total)
+	assert.Empty(t, getCostSummary(t, server.URL, period, nil).Items, "items are only returned when requested")
+}
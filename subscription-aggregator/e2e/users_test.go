@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"subscription-aggregator/internal/handler"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsers(t *testing.T) {
+	env := setupTestEnv(t, handler.WithAdminToken("e2e-admin"))
+	server := env.server
+
+	t.Run("subscription for unknown user", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/subscriptions", "application/json", jsonBody(map[string]interface{}{
+			"service_name": "Spotify", "price": 300, "user_id": uuid.New().String(), "start_date": "01-2025"}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	userID := createUser(t, server.URL)
+
+	t.Run("duplicate user", func(t *testing.T) {
+		resp, err := http.Post(server.URL+"/users", "application/json", jsonBody(map[string]string{"id": userID}))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	})
+
+	subID := createSubscription(t, server.URL, map[string]interface{}{
+		"service_name": "Spotify", "price": 300, "user_id": userID, "start_date": "01-2025"})
+
+	deleteUser := func() *http.Response {
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/users/"+userID, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer e2e-admin")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("delete requires admin", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodDelete, server.URL+"/users/"+userID, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("delete cascades to subscriptions", func(t *testing.T) {
+		resp := deleteUser()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		get, err := http.Get(server.URL + "/subscriptions/" + subID)
+		require.NoError(t, err)
+		defer get.Body.Close()
+		assert.Equal(t, http.StatusNotFound, get.StatusCode)
+
+		again := deleteUser()
+		defer again.Body.Close()
+		assert.Equal(t, http.StatusNotFound, again.StatusCode)
+	})
+}
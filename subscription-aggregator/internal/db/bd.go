@@ -9,12 +9,15 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
 )
 
-var dbConn *pgx.Conn
+var (
+	dbPool      *pgxpool.Pool
+	replicaPool *pgxpool.Pool
+)
 
 func InitDB() error {
 	if _, err := os.Stat(".env"); err == nil {
@@ -36,23 +39,50 @@ func InitDB() error {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname)
 
-	var err error
-	dbConn, err = pgx.Connect(context.Background(), dsn)
+	cfg, err := poolConfig(dsn)
+	if err != nil {
+		return fmt.Errorf("invalid PostgreSQL connection settings: %w", err)
+	}
+	dbPool, err = pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
 		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
+	// The pool connects lazily; fail startup now rather than on the first request.
+	if err := dbPool.Ping(context.Background()); err != nil {
+		return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	slog.Info("✅ Connected to PostgreSQL", "host", host, "database", dbname, "max_conns", cfg.MaxConns)
+
+	if replicaURL := os.Getenv("DB_REPLICA_URL"); replicaURL != "" {
+		cfg, err := poolConfig(replicaURL)
+		if err != nil {
+			return fmt.Errorf("invalid DB_REPLICA_URL: %w", err)
+		}
+		replicaPool, err = pgxpool.NewWithConfig(context.Background(), cfg)
+		if err != nil {
+			return fmt.Errorf("failed to connect to PostgreSQL replica: %w", err)
+		}
+		if err := replicaPool.Ping(context.Background()); err != nil {
+			return fmt.Errorf("failed to connect to PostgreSQL replica: %w", err)
+		}
+		slog.Info("✅ Connected to PostgreSQL replica")
+	}
 
-	slog.Info("✅ Connected to PostgreSQL", "host", host, "database", dbname)
 	return nil
 }
 
-func GetConn() *pgx.Conn {
-	return dbConn
+func GetConn() *pgxpool.Pool {
+	return dbPool
+}
+
+// GetReplicaConn returns the replica pool, or nil without DB_REPLICA_URL.
+func GetReplicaConn() *pgxpool.Pool {
+	return replicaPool
 }
 
 func RunMigrations() error {
-	stdlib.RegisterConnConfig(dbConn.Config())
-	sqlDB := stdlib.OpenDB(*dbConn.Config())
+	sqlDB := stdlib.OpenDB(*dbPool.Config().ConnConfig)
 	defer sqlDB.Close()
 
 	driver, err := postgres.WithInstance(sqlDB, &postgres.Config{})
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"subscription-aggregator/internal/ids"
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// monthYearPattern and the year bounds are the SQL counterpart of
+// monthdate.Parse. Four-digit years compare correctly as text.
+const monthYearPattern = `^(0[1-9]|1[0-2])-[0-9]{4}$`
+
+var (
+	minYear = strconv.Itoa(monthdate.MinYear)
+	maxYear = strconv.Itoa(monthdate.MaxYear)
+)
+
+// Conn is satisfied by both *pgx.Conn and *pgxpool.Pool.
+type Conn interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// MalformedDate is a stored start_date or end_date that is not MM-YYYY and
+// would break a conversion of the column to DATE.
+type MalformedDate struct {
+	// ID is written in the configured id scheme.
+	ID     string
+	Column string
+	Value  string
+}
+
+// FindMalformedDates lists every date value in subscriptions that does not
+// match MM-YYYY or whose year is out of range, ordered by row and column. Rows already quarantined are
+// skipped.
+func FindMalformedDates(ctx context.Context, conn Conn, scheme ids.Scheme) ([]MalformedDate, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT id, 'start_date', start_date FROM subscriptions s
+		WHERE NOT (start_date ~ $1 AND right(start_date, 4) BETWEEN $2 AND $3)
+		  AND NOT EXISTS (SELECT 1 FROM subscriptions_date_quarantine q WHERE q.id = s.id)
+		UNION ALL
+		SELECT id, 'end_date', end_date FROM subscriptions s
+		WHERE end_date IS NOT NULL AND NOT (end_date ~ $1 AND right(end_date, 4) BETWEEN $2 AND $3)
+		  AND NOT EXISTS (SELECT 1 FROM subscriptions_date_quarantine q WHERE q.id = s.id)
+		ORDER BY 1, 2 DESC`, monthYearPattern, minYear, maxYear)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan subscription dates: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (MalformedDate, error) {
+		var id uuid.UUID
+		var d MalformedDate
+		if err := row.Scan(&id, &d.Column, &d.Value); err != nil {
+			return MalformedDate{}, err
+		}
+		d.ID = scheme.Format(id)
+		return d, nil
+	})
+}
+
+var (
+	monthFirstDate = regexp.MustCompile(`^(\d{1,2})[-/.](\d{4})$`)
+	yearFirstDate  = regexp.MustCompile(`^(\d{4})[-/.](\d{1,2})$`)
+)
+
+// NormalizeMonthYear rewrites the unambiguous variants seen in legacy data,
+// such as "7-2025", "07/2025" or "2025-07", to MM-YYYY.
+func NormalizeMonthYear(s string) (string, bool) {
+	var month, year string
+	if m := monthFirstDate.FindStringSubmatch(s); m != nil {
+		month, year = m[1], m[2]
+	} else if m := yearFirstDate.FindStringSubmatch(s); m != nil {
+		month, year = m[2], m[1]
+	} else {
+		return "", false
+	}
+
+	n, _ := strconv.Atoi(month)
+	normalized := fmt.Sprintf("%02d-%s", n, year)
+	if _, err := monthdate.Parse(normalized); err != nil {
+		return "", false
+	}
+	return normalized, true
+}
+
+// quarantineColumns are copied from subscriptions to
+// subscriptions_date_quarantine by name, so column order never matters.
+const quarantineColumns = `id, service_name, price, user_id, start_date, end_date, created_at, last_accessed_at,
+	metadata, sla_uptime_pct, last_incident_at, incident_count, deleted_at, updated_at`
+
+// RepairMalformedDates fixes the values NormalizeMonthYear can repair and
+// quarantines rows with any remaining bad value, all in one transaction. A
+// quarantined row is copied to subscriptions_date_quarantine and soft-deleted,
+// so it keeps its share links and history but cannot be restored.
+func RepairMalformedDates(ctx context.Context, conn Conn, scheme ids.Scheme, found []MalformedDate) (fixed, quarantined int, err error) {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin repair: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	moved := make(map[string]bool)
+	for _, d := range found {
+		if moved[d.ID] {
+			continue
+		}
+		id, err := scheme.Parse(d.ID)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid subscription ID %q: %w", d.ID, err)
+		}
+		if value, ok := NormalizeMonthYear(d.Value); ok {
+			// Column comes from FindMalformedDates, never from user input.
+			if _, err := tx.Exec(ctx, fmt.Sprintf(`UPDATE subscriptions SET %s = $1, updated_at = NOW() WHERE id = $2`, pgx.Identifier{d.Column}.Sanitize()), value, id); err != nil {
+				return 0, 0, fmt.Errorf("failed to fix %s of %s: %w", d.Column, d.ID, err)
+			}
+			fixed++
+			continue
+		}
+
+		reason := fmt.Sprintf("malformed %s %q", d.Column, d.Value)
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO subscriptions_date_quarantine (`+quarantineColumns+`, reason)
+			SELECT `+quarantineColumns+`, $2 FROM subscriptions WHERE id = $1
+			ON CONFLICT (id) DO NOTHING`, id, reason); err != nil {
+			return 0, 0, fmt.Errorf("failed to quarantine %s: %w", d.ID, err)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE subscriptions SET deleted_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL`, id); err != nil {
+			return 0, 0, fmt.Errorf("failed to delete quarantined %s: %w", d.ID, err)
+		}
+		moved[d.ID] = true
+		quarantined++
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit repair: %w", err)
+	}
+	return fixed, quarantined, nil
+}
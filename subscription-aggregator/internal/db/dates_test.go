@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"subscription-aggregator/internal/ids"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMonthYear(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"7-2025", "07-2025", true},
+		{"07/2025", "07-2025", true},
+		{"12.2024", "12-2024", true},
+		{"2025-07", "07-2025", true},
+		{"13-2025", "", false},
+		{"00-2025", "", false},
+		{"7-1850", "", false},
+		{"2101-01", "", false},
+		{"July 2025", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeMonthYear(tt.in)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestFindAndRepairMalformedDates(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		dsn = "host=localhost port=5433 user=testuser password=testpass dbname=testdb sslmode=disable"
+	}
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(context.Background()) })
+
+	userID := uuid.New().String()
+	_, err = conn.Exec(ctx, `INSERT INTO users (id) VALUES ($1)`, userID)
+	require.NoError(t, err)
+	insert := func(start string, end *string) string {
+		var id string
+		err := conn.QueryRow(ctx, `
+			INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
+			VALUES ('Legacy', 100, $1, $2, $3) RETURNING id::text`, userID, start, end).Scan(&id)
+		require.NoError(t, err)
+		return id
+	}
+	badEnd := "someday"
+	fixable := insert("7/2025", nil)
+	broken := insert("01-2025", &badEnd)
+	ancient := insert("07-1850", nil)
+	insert("02-2025", nil)
+	t.Cleanup(func() {
+		_, _ = conn.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, userID)
+		_, _ = conn.Exec(context.Background(), `DELETE FROM subscriptions_date_quarantine WHERE user_id = $1`, userID)
+	})
+
+	found, err := FindMalformedDates(ctx, conn, ids.UUID)
+	require.NoError(t, err)
+	var ours []MalformedDate
+	for _, d := range found {
+		if d.ID == fixable || d.ID == broken || d.ID == ancient {
+			ours = append(ours, d)
+		}
+	}
+	assert.ElementsMatch(t, []MalformedDate{
+		{ID: fixable, Column: "start_date", Value: "7/2025"},
+		{ID: broken, Column: "end_date", Value: "someday"},
+		{ID: ancient, Column: "start_date", Value: "07-1850"},
+	}, ours)
+
+	fixed, quarantined, err := RepairMalformedDates(ctx, conn, ids.UUID, ours)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fixed)
+	assert.Equal(t, 2, quarantined, "out-of-range years cannot be repaired")
+
+	var start string
+	require.NoError(t, conn.QueryRow(ctx, `SELECT start_date FROM subscriptions WHERE id = $1`, fixable).Scan(&start))
+	assert.Equal(t, "07-2025", start)
+
+	var reason string
+	require.NoError(t, conn.QueryRow(ctx, `SELECT reason FROM subscriptions_date_quarantine WHERE id = $1`, broken).Scan(&reason))
+	assert.Equal(t, `malformed end_date "someday"`, reason)
+
+	var deleted bool
+	require.NoError(t, conn.QueryRow(ctx, `SELECT deleted_at IS NOT NULL FROM subscriptions WHERE id = $1`, broken).Scan(&deleted))
+	assert.True(t, deleted, "quarantined rows are soft-deleted, not removed")
+
+	found, err = FindMalformedDates(ctx, conn, ids.ULID)
+	require.NoError(t, err)
+	brokenULID := ids.ULID.Reformat(broken)
+	for _, d := range found {
+		assert.NotEqual(t, brokenULID, d.ID, "quarantined rows are not reported again")
+		assert.Len(t, d.ID, 26, "ids are reported in the configured scheme")
+	}
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// poolConfig parses dsn and applies the DB_POOL_* settings. Invalid values
+// are logged and the pgxpool default is kept. With LOG_LEVEL=debug every
+// connection gets a QueryTracer.
+func poolConfig(dsn string) (*pgxpool.Config, error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if n, ok := envInt32("DB_POOL_MAX_CONNS"); ok && n > 0 {
+		cfg.MaxConns = n
+	}
+	if n, ok := envInt32("DB_POOL_MIN_CONNS"); ok && n >= 0 {
+		cfg.MinConns = n
+	}
+	if cfg.MinConns > cfg.MaxConns {
+		slog.Warn("DB_POOL_MIN_CONNS exceeds DB_POOL_MAX_CONNS, capping", "min", cfg.MinConns, "max", cfg.MaxConns)
+		cfg.MinConns = cfg.MaxConns
+	}
+	if d, ok := envDuration("DB_POOL_MAX_CONN_LIFETIME"); ok {
+		cfg.MaxConnLifetime = d
+	}
+	if d, ok := envDuration("DB_POOL_MAX_CONN_IDLE_TIME"); ok {
+		cfg.MaxConnIdleTime = d
+	}
+
+	if os.Getenv("LOG_LEVEL") == "debug" {
+		cfg.ConnConfig.Tracer = NewQueryTracer(slog.Default())
+	}
+	return cfg, nil
+}
+
+func envInt32(key string) (int32, bool) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		slog.Warn("Invalid "+key+", using default", "value", v)
+		return 0, false
+	}
+	return int32(n), true
+}
+
+func envDuration(key string) (time.Duration, bool) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		slog.Warn("Invalid "+key+", using default", "value", v)
+		return 0, false
+	}
+	return d, true
+}
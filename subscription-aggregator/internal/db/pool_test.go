@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDSN = "host=localhost port=5433 user=testuser password=testpass dbname=testdb sslmode=disable"
+
+func TestPoolConfig_Env(t *testing.T) {
+	t.Setenv("DB_POOL_MAX_CONNS", "40")
+	t.Setenv("DB_POOL_MIN_CONNS", "5")
+	t.Setenv("DB_POOL_MAX_CONN_LIFETIME", "30m")
+	t.Setenv("DB_POOL_MAX_CONN_IDLE_TIME", "90s")
+
+	cfg, err := poolConfig(testDSN)
+	require.NoError(t, err)
+	assert.Equal(t, int32(40), cfg.MaxConns)
+	assert.Equal(t, int32(5), cfg.MinConns)
+	assert.Equal(t, 30*time.Minute, cfg.MaxConnLifetime)
+	assert.Equal(t, 90*time.Second, cfg.MaxConnIdleTime)
+	assert.Nil(t, cfg.ConnConfig.Tracer)
+}
+
+func TestPoolConfig_InvalidEnvKeepsDefaults(t *testing.T) {
+	defaults, err := pgxpool.ParseConfig(testDSN)
+	require.NoError(t, err)
+
+	t.Setenv("DB_POOL_MAX_CONNS", "many")
+	t.Setenv("DB_POOL_MIN_CONNS", "500")
+	t.Setenv("DB_POOL_MAX_CONN_LIFETIME", "-1h")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	cfg, err := poolConfig(testDSN)
+	require.NoError(t, err)
+	assert.Equal(t, defaults.MaxConns, cfg.MaxConns)
+	assert.Equal(t, cfg.MaxConns, cfg.MinConns, "min is capped at max")
+	assert.Equal(t, defaults.MaxConnLifetime, cfg.MaxConnLifetime)
+	assert.IsType(t, &QueryTracer{}, cfg.ConnConfig.Tracer)
+}
+
+func testPool(tb testing.TB, maxConns int32) *pgxpool.Pool {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		dsn = testDSN
+	}
+	cfg, err := pgxpool.ParseConfig(dsn)
+	require.NoError(tb, err)
+	cfg.MaxConns = maxConns
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	require.NoError(tb, err)
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		tb.Skipf("test database unavailable: %v", err)
+	}
+	tb.Cleanup(pool.Close)
+	return pool
+}
+
+// Fifty 200ms queries take 10s through one connection; through the pool
+// they overlap and finish in roughly the time of one.
+func TestPool_ConcurrentQueries(t *testing.T) {
+	const workers = 50
+	pool := testPool(t, workers)
+	ctx := context.Background()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := pool.Exec(ctx, "SELECT pg_sleep(0.2)")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func BenchmarkPool_ConcurrentQueries(b *testing.B) {
+	pool := testPool(b, 50)
+	ctx := context.Background()
+
+	b.SetParallelism(50)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := pool.Exec(ctx, "SELECT pg_sleep(0.001)"); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
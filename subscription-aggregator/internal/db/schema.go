@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+var expectedSubscriptionColumns = map[string]string{
+	"id":               "uuid",
+	"service_name":     "text",
+	"price":            "integer",
+	"user_id":          "uuid",
+	"start_date":       "text",
+	"end_date":         "text",
+	"created_at":       "timestamp with time zone",
+	"last_accessed_at": "timestamp with time zone",
+	"metadata":         "jsonb",
+	"sla_uptime_pct":   "numeric",
+	"last_incident_at": "timestamp with time zone",
+	"incident_count":   "integer",
+	"deleted_at":       "timestamp with time zone",
+	"updated_at":       "timestamp with time zone",
+}
+
+func ValidateSchema(ctx context.Context) error {
+	rows, err := dbPool.Query(ctx, `
+		SELECT column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'subscriptions'`)
+	if err != nil {
+		return fmt.Errorf("failed to read subscriptions schema: %w", err)
+	}
+	defer rows.Close()
+
+	actual := make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err := rows.Scan(&name, &dataType); err != nil {
+			return fmt.Errorf("failed to scan column info: %w", err)
+		}
+		actual[name] = dataType
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if mismatches := diffColumns(expectedSubscriptionColumns, actual); len(mismatches) > 0 {
+		return fmt.Errorf("subscriptions table does not match expected schema: %s", strings.Join(mismatches, "; "))
+	}
+
+	slog.Info("✅ Database schema validated")
+	return nil
+}
+
+func diffColumns(expected, actual map[string]string) []string {
+	if len(actual) == 0 {
+		return []string{"table is missing"}
+	}
+
+	var mismatches []string
+	for name, wantType := range expected {
+		gotType, ok := actual[name]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("column %q is missing (want %s)", name, wantType))
+			continue
+		}
+		if gotType != wantType {
+			mismatches = append(mismatches, fmt.Sprintf("column %q has type %s (want %s)", name, gotType, wantType))
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testExpectedColumns = map[string]string{
+	"id":           "uuid",
+	"service_name": "text",
+	"price":        "integer",
+	"user_id":      "uuid",
+	"start_date":   "text",
+	"end_date":     "text",
+}
+
+func TestDiffColumns_Matches(t *testing.T) {
+	actual := map[string]string{}
+	for name, dataType := range expectedSubscriptionColumns {
+		actual[name] = dataType
+	}
+	actual["extra"] = "text"
+
+	assert.Empty(t, diffColumns(expectedSubscriptionColumns, actual))
+}
+
+func TestDiffColumns_WrongSchema(t *testing.T) {
+	actual := map[string]string{
+		"id":           "uuid",
+		"service_name": "text",
+		"price":        "numeric",
+		"user_id":      "uuid",
+		"start_date":   "date",
+	}
+
+	assert.Equal(t, []string{
+		`column "end_date" is missing (want text)`,
+		`column "price" has type numeric (want integer)`,
+		`column "start_date" has type date (want text)`,
+	}, diffColumns(testExpectedColumns, actual))
+}
+
+func TestDiffColumns_MissingTable(t *testing.T) {
+	assert.Equal(t, []string{"table is missing"}, diffColumns(expectedSubscriptionColumns, nil))
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	placeholderRegex   = regexp.MustCompile(`\$\d+`)
+	stringLiteralRegex = regexp.MustCompile(`'(?:[^']|'')*'`)
+)
+
+// QueryTracer logs every query at DEBUG with its duration. Parameter
+// placeholders and string literals are replaced with ? so values never
+// reach the log.
+type QueryTracer struct {
+	logger *slog.Logger
+}
+
+func NewQueryTracer(logger *slog.Logger) *QueryTracer {
+	return &QueryTracer{logger: logger}
+}
+
+type traceKey struct{}
+
+type traceStart struct {
+	sql   string
+	start time.Time
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceStart{sql: data.SQL, start: time.Now()})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	st, ok := ctx.Value(traceKey{}).(traceStart)
+	if !ok {
+		return
+	}
+	attrs := []any{
+		"sql", sanitizeSQL(st.sql),
+		"duration_ms", float64(time.Since(st.start).Microseconds()) / 1000,
+		"rows", data.CommandTag.RowsAffected(),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, "error", data.Err)
+	}
+	t.logger.DebugContext(ctx, "Query", attrs...)
+}
+
+func sanitizeSQL(sql string) string {
+	sql = stringLiteralRegex.ReplaceAllString(sql, "?")
+	sql = placeholderRegex.ReplaceAllString(sql, "?")
+	return strings.Join(strings.Fields(sql), " ")
+}
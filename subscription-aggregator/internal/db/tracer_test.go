@@ -0,0 +1,47 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	sql := `
+		SELECT id FROM subscriptions
+		WHERE user_id = $1 AND service_name = 'It''s secret' AND price > $12`
+	assert.Equal(t, "SELECT id FROM subscriptions WHERE user_id = ? AND service_name = ? AND price > ?", sanitizeSQL(sql))
+}
+
+func TestQueryTracer_LogsAtDebug(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewQueryTracer(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL:  "DELETE FROM subscriptions WHERE id = $1",
+		Args: []any{"6f1c2b9e-secret"},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("DELETE 1")})
+
+	out := buf.String()
+	assert.Contains(t, out, "level=DEBUG")
+	assert.Contains(t, out, `sql="DELETE FROM subscriptions WHERE id = ?"`)
+	assert.Contains(t, out, "duration_ms=")
+	assert.Contains(t, out, "rows=1")
+	assert.NotContains(t, out, "6f1c2b9e-secret")
+}
+
+func TestQueryTracer_SilentAboveDebug(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewQueryTracer(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	assert.Empty(t, buf.String())
+}
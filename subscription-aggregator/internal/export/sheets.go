@@ -0,0 +1,76 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"subscription-aggregator/internal/model"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+// SheetsExporter appends subscriptions to a sheet and reports how many rows
+// were written.
+type SheetsExporter interface {
+	Export(ctx context.Context, spreadsheetID, sheetName string, subs []model.Subscription) (int, error)
+}
+
+// SpreadsheetURL is the browser URL of a spreadsheet.
+func SpreadsheetURL(spreadsheetID string) string {
+	return "https://docs.google.com/spreadsheets/d/" + url.PathEscape(spreadsheetID)
+}
+
+// GoogleSheetsExporter appends rows through the Sheets v4 API, authenticating
+// as a service account.
+type GoogleSheetsExporter struct {
+	service *sheets.Service
+}
+
+// NewGoogleSheetsExporter builds an exporter from the contents of a service
+// account key file. Extra options, such as a custom endpoint, are passed to
+// the Sheets client.
+func NewGoogleSheetsExporter(ctx context.Context, credentialsJSON []byte, opts ...option.ClientOption) (*GoogleSheetsExporter, error) {
+	opts = append([]option.ClientOption{
+		option.WithCredentialsJSON(credentialsJSON),
+		option.WithScopes(sheets.SpreadsheetsScope),
+	}, opts...)
+	service, err := sheets.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create sheets client: %w", err)
+	}
+	return &GoogleSheetsExporter{service: service}, nil
+}
+
+func (e *GoogleSheetsExporter) Export(ctx context.Context, spreadsheetID, sheetName string, subs []model.Subscription) (int, error) {
+	if len(subs) == 0 {
+		return 0, nil
+	}
+	resp, err := e.service.Spreadsheets.Values.Append(spreadsheetID, sheetName, &sheets.ValueRange{Values: sheetRows(subs)}).
+		ValueInputOption("RAW").
+		InsertDataOption("INSERT_ROWS").
+		Context(ctx).
+		Do()
+	if err != nil {
+		return 0, fmt.Errorf("append rows: %w", err)
+	}
+	if resp.Updates == nil {
+		return 0, nil
+	}
+	return int(resp.Updates.UpdatedRows), nil
+}
+
+// sheetRows lays out subscriptions as sheet rows in a fixed column order:
+// id, service_name, price, user_id, start_date, end_date.
+func sheetRows(subs []model.Subscription) [][]any {
+	rows := make([][]any, 0, len(subs))
+	for _, s := range subs {
+		end := ""
+		if s.EndDate != nil {
+			end = s.EndDate.String()
+		}
+		rows = append(rows, []any{s.ID, s.ServiceName, s.Price, s.UserID, s.StartDate.String(), end})
+	}
+	return rows
+}
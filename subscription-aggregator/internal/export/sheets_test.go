@@ -0,0 +1,111 @@
+package export
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+)
+
+func TestGoogleSheetsExporter_Export(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var tokenRequests int
+	var appended [][]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		require.NoError(t, r.ParseForm())
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(r.PostForm.Get("assertion"), claims, func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "exporter@example.iam.gserviceaccount.com", claims["iss"])
+		assert.Equal(t, sheets.SpreadsheetsScope, claims["scope"])
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"tok","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("POST /sheets/v4/spreadsheets/sheet-1/values/{range}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		assert.Equal(t, "Subscriptions:append", r.PathValue("range"))
+		var body struct {
+			Values [][]any `json:"values"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		appended = append(appended, body.Values...)
+		json.NewEncoder(w).Encode(map[string]any{"updates": map[string]int{"updatedRows": len(body.Values)}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "exporter@example.iam.gserviceaccount.com",
+		"private_key":  string(keyPEM),
+		"token_uri":    srv.URL + "/token",
+	})
+	e, err := NewGoogleSheetsExporter(context.Background(), creds, option.WithEndpoint(srv.URL+"/sheets/"))
+	require.NoError(t, err)
+
+	end := monthdate.New(2025, 12)
+	subs := []model.Subscription{
+		{ID: "a", ServiceName: "Netflix", Price: 500, UserID: "u", StartDate: monthdate.New(2025, 1), EndDate: &end},
+		{ID: "b", ServiceName: "Spotify", Price: 300, UserID: "u", StartDate: monthdate.New(2025, 3)},
+	}
+	for range 2 {
+		n, err := e.Export(context.Background(), "sheet-1", "Subscriptions", subs)
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	}
+
+	assert.Equal(t, 1, tokenRequests, "access token should be reused")
+	require.Len(t, appended, 4)
+	assert.Equal(t, []any{"a", "Netflix", float64(500), "u", "01-2025", "12-2025"}, appended[0])
+	assert.Equal(t, []any{"b", "Spotify", float64(300), "u", "03-2025", ""}, appended[1])
+}
+
+func TestGoogleSheetsExporter_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	creds, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "exporter@example.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":    srv.URL,
+	})
+	e, err := NewGoogleSheetsExporter(context.Background(), creds, option.WithEndpoint(srv.URL+"/"))
+	require.NoError(t, err)
+
+	_, err = e.Export(context.Background(), "sheet-1", "Subscriptions", []model.Subscription{{ID: "a"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid_grant")
+}
+
+func TestNewGoogleSheetsExporter_InvalidCredentials(t *testing.T) {
+	_, err := NewGoogleSheetsExporter(context.Background(), []byte(`{"client_email":"x"}`))
+	assert.Error(t, err)
+	_, err = NewGoogleSheetsExporter(context.Background(), []byte(`not json`))
+	assert.Error(t, err)
+}
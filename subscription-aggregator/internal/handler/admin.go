@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+)
+
+const (
+	defaultServiceUsageLimit = 20
+	maxServiceUsageLimit     = 100
+	defaultRetentionMonths   = 12
+	maxRetentionMonths       = 120
+)
+
+// WithAdminToken enables the /admin routes, authorised by
+// "Authorization: Bearer <token>". Without a token they are not registered.
+func WithAdminToken(token string) Option {
+	return func(h *SubscriptionHandler) {
+		h.adminToken = token
+	}
+}
+
+func (h *SubscriptionHandler) registerAdminRoutes(mux *http.ServeMux) {
+	if h.adminToken == "" {
+		return
+	}
+	mux.HandleFunc("GET /admin/service-usage", h.timeout(h.slowRouteTimeout, h.requireAdmin(h.knownParams(h.GetServiceUsage, "limit", "offset"))))
+	mux.HandleFunc("GET /admin/retention", h.timeout(h.slowRouteTimeout, h.requireAdmin(h.knownParams(h.GetRetention, "cohort_month", "months"))))
+	// Medians are computed over every user's prices, so this is admin-only.
+	mux.HandleFunc("GET /subscriptions/anomalies", h.timeout(h.slowRouteTimeout, h.requireAdmin(h.knownParams(h.GetPriceAnomalies, "user_id"))))
+}
+
+func (h *SubscriptionHandler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.authorizeAdmin(w, r) {
+			next(w, r)
+		}
+	}
+}
+
+// authorizeAdmin checks the admin bearer token, writing the 401/403 response
+// itself when it is missing or wrong. Without a configured admin token every
+// request is refused.
+func (h *SubscriptionHandler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, `{"error": "admin authentication required"}`, http.StatusUnauthorized)
+		return false
+	}
+	if h.adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) != 1 {
+		http.Error(w, `{"error": "admin role required"}`, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (h *SubscriptionHandler) GetServiceUsage(w http.ResponseWriter, r *http.Request) {
+	limit, offset, errs := parsePage(r.URL.Query(), defaultServiceUsageLimit, maxServiceUsageLimit)
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	usage, total, err := h.repo.ServiceUsage(r.Context(), limit, offset)
+	if err != nil {
+		slog.Error("Service usage failed", "error", err)
+		writeRepoError(w, err, "failed to load service usage")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.NewPaginatedResponse(usage, total, limit, offset))
+}
+
+func (h *SubscriptionHandler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	cohort, err := monthdate.Parse(r.URL.Query().Get("cohort_month"))
+	if err != nil {
+		http.Error(w, `{"error": "cohort_month must be in MM-YYYY format (e.g., 01-2025)"}`, http.StatusBadRequest)
+		return
+	}
+
+	months := defaultRetentionMonths
+	if v := r.URL.Query().Get("months"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 || parsed > maxRetentionMonths {
+			http.Error(w, fmt.Sprintf(`{"error": "months must be an integer between 1 and %d"}`, maxRetentionMonths), http.StatusBadRequest)
+			return
+		}
+		months = parsed
+	}
+
+	retention, err := h.repo.Retention(r.Context(), cohort, months)
+	if err != nil {
+		slog.Error("Retention failed", "cohort_month", cohort, "error", err)
+		writeRepoError(w, err, "failed to compute retention")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, retention)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type usageRepo struct {
+	repository.SubscriptionRepository
+	limit, offset int
+}
+
+func (u *usageRepo) ServiceUsage(ctx context.Context, limit, offset int) ([]model.ServiceUsage, int, error) {
+	u.limit, u.offset = limit, offset
+	return []model.ServiceUsage{{ServiceName: "Spotify", Subscriptions: 1250, Users: 980, AvgPrice: 219}}, 3, nil
+}
+
+func (u *usageRepo) PriceAnomalies(ctx context.Context, userID string, multiplier float64) ([]model.PriceAnomaly, error) {
+	return []model.PriceAnomaly{{ID: "a", ServiceName: "Spotify", Price: 1200, MedianPrice: 300, Ratio: 4}}, nil
+}
+
+func (u *usageRepo) Retention(ctx context.Context, cohort monthdate.MonthDate, months int) (model.Retention, error) {
+	u.limit = months
+	return model.Retention{CohortMonth: cohort.String(), CohortSize: 2, Points: []model.RetentionPoint{
+		{MonthsAfter: 0, Month: cohort.String(), Active: 2, Rate: 1},
+		{MonthsAfter: 1, Month: cohort.AddMonths(1).String(), Active: 1, Rate: 0.5},
+	}}, nil
+}
+
+func adminRequest(target, token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestServiceUsage_RequiresAdmin(t *testing.T) {
+	h := NewSubscriptionHandler(&usageRepo{}, WithAdminToken("s3cret"))
+
+	rec := serve(h, adminRequest("/admin/service-usage", ""))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(h, adminRequest("/admin/service-usage", "wrong"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestServiceUsage_DisabledWithoutToken(t *testing.T) {
+	h := NewSubscriptionHandler(&usageRepo{})
+
+	rec := serve(h, adminRequest("/admin/service-usage", "anything"))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServiceUsage(t *testing.T) {
+	repo := &usageRepo{}
+	h := NewSubscriptionHandler(repo, WithAdminToken("s3cret"))
+
+	rec := serve(h, adminRequest("/admin/service-usage", "s3cret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, defaultServiceUsageLimit, repo.limit)
+	assert.JSONEq(t, `{"data":[{"service_name":"Spotify","subscriptions":1250,"users":980,"avg_price":219}],
+		"pagination":{"total":3,"limit":20,"offset":0,"has_next":true,"has_prev":false}}`, rec.Body.String())
+
+	rec = serve(h, adminRequest("/admin/service-usage?limit=1&offset=2", "s3cret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, repo.limit)
+	assert.Equal(t, 2, repo.offset)
+
+	rec = serve(h, adminRequest("/admin/service-usage?limit=500&offset=-1", "s3cret"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error": "limit must be between 1 and 100; offset must not be negative"}`, rec.Body.String())
+}
+
+func TestPriceAnomalies(t *testing.T) {
+	h := NewSubscriptionHandler(&usageRepo{}, WithAdminToken("s3cret"))
+	userID := "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+
+	rec := serve(h, adminRequest("/subscriptions/anomalies?user_id="+userID, ""))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(h, adminRequest("/subscriptions/anomalies?user_id=nope", "s3cret"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, adminRequest("/subscriptions/anomalies?user_id="+userID, "s3cret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"id":"a","service_name":"Spotify","price":1200,"median_price":300,"ratio":4}]`, rec.Body.String())
+}
+
+func TestRetention(t *testing.T) {
+	repo := &usageRepo{}
+	h := NewSubscriptionHandler(repo, WithAdminToken("s3cret"))
+
+	rec := serve(h, adminRequest("/admin/retention?cohort_month=01-2025", ""))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(h, adminRequest("/admin/retention?cohort_month=01-2025&months=1", "s3cret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, repo.limit)
+	assert.JSONEq(t, `{"cohort_month":"01-2025","cohort_size":2,"points":[
+		{"months_after":0,"month":"01-2025","active":2,"rate":1},
+		{"months_after":1,"month":"02-2025","active":1,"rate":0.5}]}`, rec.Body.String())
+
+	rec = serve(h, adminRequest("/admin/retention?cohort_month=01-2025", "s3cret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, defaultRetentionMonths, repo.limit)
+
+	for _, q := range []string{"", "cohort_month=2025-01", "cohort_month=01-2025&months=0", "cohort_month=01-2025&months=500"} {
+		rec = serve(h, adminRequest("/admin/retention?"+q, "s3cret"))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, q)
+	}
+}
+
+func TestListSubscriptions_IncludeDeletedRequiresAdmin(t *testing.T) {
+	target := "/subscriptions?include_deleted=true&user_id=" + uuid.NewString()
+
+	h := NewSubscriptionHandler(countRepo{}, WithAdminToken("s3cret"))
+	assert.Equal(t, http.StatusUnauthorized, serve(h, adminRequest(target, "")).Code)
+	assert.Equal(t, http.StatusForbidden, serve(h, adminRequest(target, "wrong")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, adminRequest(target, "s3cret")).Code)
+
+	h = NewSubscriptionHandler(countRepo{})
+	assert.Equal(t, http.StatusForbidden, serve(h, adminRequest(target, "anything")).Code,
+		"without an admin token nobody may list deleted subscriptions")
+}
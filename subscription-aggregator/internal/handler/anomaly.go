@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+)
+
+const (
+	anomalyHistoryMonths = 3
+	// priceAnomalyMultiplier flags prices above this multiple of the
+	// service's median price.
+	priceAnomalyMultiplier = 3
+)
+
+func (h *SubscriptionHandler) GetCostAnomaly(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	now := h.clock.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	current, err := h.monthCost(r, userID, thisMonth)
+	if err != nil {
+		slog.Error("Cost anomaly calculation failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to calculate cost anomaly")
+		return
+	}
+
+	history := make([]int, 0, anomalyHistoryMonths)
+	for i := 1; i <= anomalyHistoryMonths; i++ {
+		cost, err := h.monthCost(r, userID, thisMonth.AddDate(0, -i, 0))
+		if err != nil {
+			slog.Error("Cost anomaly calculation failed", "user_id", userID, "error", err)
+			writeRepoError(w, err, "failed to calculate cost anomaly")
+			return
+		}
+		history = append(history, cost)
+	}
+
+	writeJSON(w, http.StatusOK, detectCostAnomaly(current, history, h.anomalyMultiplier))
+}
+
+func (h *SubscriptionHandler) monthCost(r *http.Request, userID string, t time.Time) (int, error) {
+	month := monthdate.FromTime(t).String()
+	summary, err := h.repo.TotalCost(r.Context(), repository.CostFilter{UserID: userID, From: month, To: month})
+	return summary.Total, err
+}
+
+func detectCostAnomaly(current int, history []int, multiplier float64) model.CostAnomaly {
+	sum := 0
+	for _, cost := range history {
+		sum += cost
+	}
+	avg := 0.0
+	if len(history) > 0 {
+		avg = float64(sum) / float64(len(history))
+	}
+
+	result := model.CostAnomaly{
+		CurrentMonthCost: current,
+		ThreeMonthAvg:    math.Round(avg*100) / 100,
+		IsAnomaly:        float64(current) > avg*multiplier,
+	}
+	if avg > 0 {
+		deviation := math.Round((float64(current)-avg)/avg*1000) / 10
+		result.DeviationPct = &deviation
+	}
+	return result
+}
+
+func (h *SubscriptionHandler) GetPriceAnomalies(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	anomalies, err := h.repo.PriceAnomalies(r.Context(), userID, priceAnomalyMultiplier)
+	if err != nil {
+		slog.Error("Price anomaly lookup failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to find price anomalies")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, anomalies)
+}
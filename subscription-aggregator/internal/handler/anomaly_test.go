@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCostAnomaly(t *testing.T) {
+	result := detectCostAnomaly(5200, []int{2000, 2100, 2200}, 1.5)
+
+	assert.Equal(t, 5200, result.CurrentMonthCost)
+	assert.Equal(t, 2100.0, result.ThreeMonthAvg)
+	assert.True(t, result.IsAnomaly)
+	require.NotNil(t, result.DeviationPct)
+	assert.Equal(t, 147.6, *result.DeviationPct)
+}
+
+func TestDetectCostAnomaly_WithinMultiplier(t *testing.T) {
+	result := detectCostAnomaly(3000, []int{2000, 2100, 2200}, 1.5)
+
+	assert.False(t, result.IsAnomaly)
+	require.NotNil(t, result.DeviationPct)
+	assert.Equal(t, 42.9, *result.DeviationPct)
+}
+
+func TestDetectCostAnomaly_NoHistory(t *testing.T) {
+	result := detectCostAnomaly(500, []int{0, 0, 0}, 1.5)
+
+	assert.True(t, result.IsAnomaly)
+	assert.Nil(t, result.DeviationPct)
+}
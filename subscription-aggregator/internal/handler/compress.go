@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	DefaultGzipLevel    = gzip.DefaultCompression
+	DefaultGzipMinBytes = 1024
+)
+
+// GzipMiddleware compresses responses of at least minBytes for clients that
+// accept gzip. Smaller responses are sent as-is, since compressing them
+// costs more CPU than it saves bandwidth. level is a compress/gzip level.
+func GzipMiddleware(level, minBytes int) (Middleware, error) {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		return nil, fmt.Errorf("gzip level must be between %d and %d", gzip.HuffmanOnly, gzip.BestCompression)
+	}
+	if minBytes < 0 {
+		return nil, fmt.Errorf("gzip minimum size must not be negative")
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipResponseWriter{ResponseWriter: w, level: level, minBytes: minBytes, status: http.StatusOK}
+			defer gw.Close()
+			next.ServeHTTP(gw, r)
+		})
+	}, nil
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter holds the body back until minBytes have been written,
+// then decides once whether the whole response is compressed.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	level    int
+	minBytes int
+
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+	gz          *gzip.Writer
+	// decided is set once the response is committed, compressed or not.
+	decided bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	g.status = status
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	g.wroteHeader = true
+	if g.decided {
+		if g.gz != nil {
+			return g.gz.Write(p)
+		}
+		return g.ResponseWriter.Write(p)
+	}
+	g.buf.Write(p)
+	if g.buf.Len() >= g.minBytes {
+		if err := g.commit(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// commit sends the status line and the buffered body, compressing when
+// compress is set and nothing upstream has already encoded the response.
+func (g *gzipResponseWriter) commit(compress bool) error {
+	g.decided = true
+	h := g.ResponseWriter.Header()
+	if h.Get("Content-Encoding") != "" || g.status == http.StatusNoContent || g.status == http.StatusNotModified {
+		compress = false
+	}
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		// The level was validated when the middleware was built.
+		g.gz, _ = gzip.NewWriterLevel(g.ResponseWriter, g.level)
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+	if g.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if g.gz != nil {
+		_, err = g.gz.Write(g.buf.Bytes())
+	} else {
+		_, err = g.ResponseWriter.Write(g.buf.Bytes())
+	}
+	g.buf.Reset()
+	return err
+}
+
+func (g *gzipResponseWriter) Close() {
+	if !g.decided {
+		if err := g.commit(false); err != nil {
+			slog.Error("Failed to write response", "error", err)
+			return
+		}
+	}
+	if g.gz != nil {
+		if err := g.gz.Close(); err != nil {
+			slog.Error("Failed to finish gzip response", "error", err)
+		}
+	}
+}
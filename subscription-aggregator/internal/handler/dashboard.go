@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// GetUserDashboard returns a user's subscriptions together with their total
+// cost for the from..to period, loaded in one database round-trip.
+func (h *SubscriptionHandler) GetUserDashboard(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	userID := r.PathValue("user_id")
+	if err := validateUserID(userID); err != nil {
+		errs = append(errs, err.Error())
+	}
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if err := h.validatePeriod(from, to); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	dashboard, err := h.repo.GetUserDashboard(r.Context(), userID, from, to)
+	if err != nil {
+		slog.Error("Load user dashboard failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to load dashboard")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dashboard)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"subscription-aggregator/internal/model"
+	apptime "subscription-aggregator/internal/time"
+)
+
+// EnvelopeMiddleware wraps successful JSON responses in model.Envelope so
+// clients can see which build answered. Errors, empty responses, non-JSON
+// bodies such as exports, /version and the probes are passed through
+// unchanged.
+func EnvelopeMiddleware(serverVersion, commit string, clock apptime.ClockSource) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isProbe(r) || r.URL.Path == "/version" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ew := &envelopeResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+			if !ew.buffering {
+				return
+			}
+
+			env := model.Envelope{
+				APIVersion:    apiVersion,
+				ServerVersion: serverVersion,
+				Commit:        commit,
+				Timestamp:     clock.Now().UTC(),
+				Data:          json.RawMessage(bytes.TrimSpace(ew.buf.Bytes())),
+			}
+			if !json.Valid(env.Data) {
+				slog.Warn("Response is not valid JSON, sending it without an envelope", "path", r.URL.Path)
+				w.WriteHeader(ew.status)
+				w.Write(ew.buf.Bytes())
+				return
+			}
+			w.Header().Del("Content-Length")
+			w.WriteHeader(ew.status)
+			if err := json.NewEncoder(w).Encode(env); err != nil {
+				slog.Warn("Failed to write response", "error", err)
+			}
+		})
+	}
+}
+
+// envelopeResponseWriter holds back successful JSON bodies so they can be
+// wrapped once the handler returns; anything else is written straight through.
+type envelopeResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	buf         bytes.Buffer
+}
+
+func (e *envelopeResponseWriter) WriteHeader(status int) {
+	if e.wroteHeader {
+		return
+	}
+	e.wroteHeader = true
+	e.status = status
+	mediaType, _, _ := mime.ParseMediaType(e.Header().Get("Content-Type"))
+	if status >= 200 && status < 300 && status != http.StatusNoContent && mediaType == "application/json" {
+		e.buffering = true
+		return
+	}
+	e.ResponseWriter.WriteHeader(status)
+}
+
+func (e *envelopeResponseWriter) Write(p []byte) (int, error) {
+	if !e.wroteHeader {
+		e.WriteHeader(http.StatusOK)
+	}
+	if e.buffering {
+		return e.buf.Write(p)
+	}
+	return e.ResponseWriter.Write(p)
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+)
+
+// ExportSubscriptions returns a user's subscriptions either as a plain JSON
+// array (format=json, the default) or as Terraform JSON resources.
+func (h *SubscriptionHandler) ExportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	var errs paramErrors
+	userID, err := parseUserID(r.URL.Query())
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "terraform" {
+		errs = append(errs, "format must be one of: json, terraform")
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.repo.ListByUserID(r.Context(), userID, repository.ListOptions{})
+	if err != nil {
+		slog.Error("Export subscriptions failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to export subscriptions")
+		return
+	}
+
+	if format == "terraform" {
+		writeJSON(w, http.StatusOK, terraformState(subs))
+		return
+	}
+	writeJSON(w, http.StatusOK, subs)
+}
+
+// terraformState maps each subscription to a resource named after its
+// service. Names that would collide get a numeric suffix.
+func terraformState(subs []model.Subscription) model.TerraformState {
+	state := model.TerraformState{Resources: make([]model.TerraformResource, 0, len(subs))}
+	used := make(map[string]bool, len(subs))
+	for _, s := range subs {
+		base := terraformName(s.ServiceName)
+		name := base
+		for n := 2; used[name]; n++ {
+			name = base + "_" + strconv.Itoa(n)
+		}
+		used[name] = true
+		state.Resources = append(state.Resources, model.TerraformResource{
+			Type:   "subscription",
+			Name:   name,
+			Values: s,
+		})
+	}
+	return state
+}
+
+// terraformName turns a service name into a valid Terraform identifier:
+// lowercase letters, digits and underscores, not starting with a digit.
+func terraformName(serviceName string) string {
+	var b strings.Builder
+	underscore := false
+	for _, c := range strings.ToLower(serviceName) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "_")
+	if name == "" {
+		return "subscription"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// exportPageSize bounds how many subscriptions ExportUserData holds in
+// memory at once.
+var exportPageSize = 500
+
+// ExportUserData streams a zip archive with one JSON file per entity type
+// owned by the user. Subscriptions, soft-deleted ones included, are currently
+// the only such entity.
+func (h *SubscriptionHandler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("user_id")
+	if err := validateUserID(userID); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	// Load the first page before writing so a failing query can still
+	// produce an error status.
+	opts := repository.ListOptions{IncludeDeleted: true, Sort: "created_at_asc", Limit: exportPageSize}
+	page, err := h.repo.ListByUserID(r.Context(), userID, opts)
+	if err != nil {
+		slog.Error("Export subscriptions failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to export user data")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%s.zip"`, userID))
+	w.WriteHeader(http.StatusOK)
+
+	zw := zip.NewWriter(w)
+	fw, err := zw.Create("subscriptions.json")
+	if err != nil {
+		slog.Error("Failed to add file to export", "user_id", userID, "file", "subscriptions.json", "error", err)
+		return
+	}
+	aw := newJSONArrayWriter(fw)
+	for {
+		for _, sub := range page {
+			if err := aw.write(sub); err != nil {
+				slog.Error("Failed to write export file", "user_id", userID, "file", "subscriptions.json", "error", err)
+				return
+			}
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+		// Rows are ordered by creation and soft deletes keep them in place,
+		// so offsets stay stable while the export runs.
+		opts.Offset += len(page)
+		if page, err = h.repo.ListByUserID(r.Context(), userID, opts); err != nil {
+			// The status is already sent; leaving the archive unfinished
+			// makes the failure visible to the client.
+			slog.Error("Export subscriptions failed", "user_id", userID, "offset", opts.Offset, "error", err)
+			return
+		}
+	}
+	if err := aw.close(); err != nil {
+		slog.Error("Failed to write export file", "user_id", userID, "file", "subscriptions.json", "error", err)
+		return
+	}
+	if err := zw.Close(); err != nil {
+		slog.Error("Failed to finish export archive", "user_id", userID, "error", err)
+	}
+}
+
+// jsonArrayWriter writes an indented JSON array one element at a time.
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	return &jsonArrayWriter{w: w}
+}
+
+func (a *jsonArrayWriter) write(v any) error {
+	data, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if a.count == 0 {
+		sep = "[\n  "
+	}
+	a.count++
+	if _, err := io.WriteString(a.w, sep); err != nil {
+		return err
+	}
+	_, err = a.w.Write(data)
+	return err
+}
+
+func (a *jsonArrayWriter) close() error {
+	end := "\n]\n"
+	if a.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
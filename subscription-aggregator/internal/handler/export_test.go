@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformName(t *testing.T) {
+	tests := map[string]string{
+		"Spotify":          "spotify",
+		"Yandex Plus":      "yandex_plus",
+		"  Apple  Music! ": "apple_music",
+		"1Password":        "_1password",
+		"Кинопоиск":        "subscription",
+		"":                 "subscription",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, terraformName(in), in)
+	}
+}
+
+func TestTerraformState(t *testing.T) {
+	subs := []model.Subscription{
+		{ID: "a", ServiceName: "Netflix", Price: 800},
+		{ID: "b", ServiceName: "Netflix 2", Price: 500},
+		{ID: "c", ServiceName: "netflix", Price: 300},
+	}
+
+	state := terraformState(subs)
+
+	var names []string
+	for i, res := range state.Resources {
+		assert.Equal(t, "subscription", res.Type)
+		assert.Equal(t, subs[i], res.Values)
+		names = append(names, res.Name)
+	}
+	assert.Equal(t, []string{"netflix", "netflix_2", "netflix_3"}, names)
+	assert.NotNil(t, terraformState(nil).Resources, "empty export still encodes as []")
+}
+
+// pagedRepo serves ListByUserID in Limit/Offset pages and records every call.
+type pagedRepo struct {
+	repository.SubscriptionRepository
+	subs   []model.Subscription
+	calls  []repository.ListOptions
+	failAt int
+}
+
+func (p *pagedRepo) ListByUserID(ctx context.Context, userID string, opts repository.ListOptions) ([]model.Subscription, error) {
+	p.calls = append(p.calls, opts)
+	if p.failAt > 0 && len(p.calls) == p.failAt {
+		return nil, errors.New("database query failed: connection reset")
+	}
+	start := min(opts.Offset, len(p.subs))
+	return p.subs[start:min(start+opts.Limit, len(p.subs))], nil
+}
+
+func exportedSubscriptions(t *testing.T, body []byte) []model.Subscription {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	require.Equal(t, "subscriptions.json", zr.File[0].Name)
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer f.Close()
+
+	var subs []model.Subscription
+	require.NoError(t, json.NewDecoder(f).Decode(&subs))
+	return subs
+}
+
+func TestExportUserData_StreamsAllPages(t *testing.T) {
+	defer func(n int) { exportPageSize = n }(exportPageSize)
+	exportPageSize = 2
+
+	userID := uuid.NewString()
+	deletedAt := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	repo := &pagedRepo{}
+	for _, name := range []string{"Spotify", "Netflix", "Yandex Plus", "Kinopoisk", "Okko"} {
+		repo.subs = append(repo.subs, model.Subscription{ID: uuid.NewString(), ServiceName: name, Price: 300, UserID: userID,
+			StartDate: monthdate.New(2025, time.January)})
+	}
+	repo.subs[3].DeletedAt = &deletedAt
+	h := NewSubscriptionHandler(repo)
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/users/"+userID+"/export", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+
+	subs := exportedSubscriptions(t, rec.Body.Bytes())
+	assert.Equal(t, repo.subs, subs)
+	require.NotNil(t, subs[3].DeletedAt, "soft-deleted subscriptions are exported")
+
+	require.Len(t, repo.calls, 3)
+	for i, opts := range repo.calls {
+		assert.True(t, opts.IncludeDeleted)
+		assert.Equal(t, 2, opts.Limit)
+		assert.Equal(t, 2*i, opts.Offset)
+	}
+
+	rec = serve(NewSubscriptionHandler(&pagedRepo{}), httptest.NewRequest(http.MethodGet, "/users/"+uuid.NewString()+"/export", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, exportedSubscriptions(t, rec.Body.Bytes()))
+}
+
+func TestExportUserData_FailureMidStream(t *testing.T) {
+	defer func(n int) { exportPageSize = n }(exportPageSize)
+	exportPageSize = 1
+
+	userID := uuid.NewString()
+	repo := &pagedRepo{failAt: 2, subs: []model.Subscription{
+		{ID: uuid.NewString(), ServiceName: "Spotify", UserID: userID},
+		{ID: uuid.NewString(), ServiceName: "Netflix", UserID: userID},
+	}}
+	rec := serve(NewSubscriptionHandler(repo), httptest.NewRequest(http.MethodGet, "/users/"+userID+"/export", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code, "the status is sent with the first page")
+	_, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.Error(t, err, "a failed export must not look like a complete archive")
+
+	repo = &pagedRepo{failAt: 1}
+	rec = serve(NewSubscriptionHandler(repo), httptest.NewRequest(http.MethodGet, "/users/"+userID+"/export", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	apptime "subscription-aggregator/internal/time"
+)
+
+// Health tracks the server lifecycle for Kubernetes probes. Until startup
+// completes (migrations applied, routes registered) both probes report 503;
+// liveness gives up waiting after the startup window so a hung start is
+// eventually restarted. While draining, /readyz reports 503 so load
+// balancers deregister the instance.
+type Health struct {
+	clock           apptime.ClockSource
+	startupDeadline time.Time
+	started         atomic.Bool
+	draining        atomic.Bool
+}
+
+func NewHealth(clock apptime.ClockSource, startupWindow time.Duration) *Health {
+	return &Health{clock: clock, startupDeadline: clock.Now().Add(startupWindow)}
+}
+
+func (hc *Health) MarkStarted() {
+	hc.started.Store(true)
+}
+
+func (hc *Health) Started() bool {
+	return hc.started.Load() || !hc.clock.Now().Before(hc.startupDeadline)
+}
+
+func (hc *Health) SetDraining(draining bool) {
+	hc.draining.Store(draining)
+}
+
+func (hc *Health) Draining() bool {
+	return hc.draining.Load()
+}
+
+func (hc *Health) Live(w http.ResponseWriter, r *http.Request) {
+	if !hc.Started() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "starting"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "alive"})
+}
+
+func (hc *Health) Readyz(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case hc.Draining():
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "draining"})
+	case !hc.started.Load():
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "starting"})
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	}
+}
+
+func isProbe(r *http.Request) bool {
+	return r.URL.Path == "/readyz" || r.URL.Path == "/health/live"
+}
+
+// RejectWhileDraining answers new requests with 503 once draining starts,
+// except the probes themselves.
+func (hc *Health) RejectWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hc.Draining() && !isProbe(r) {
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, `{"error": "server is shutting down"}`, http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
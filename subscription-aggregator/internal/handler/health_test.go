@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStartedHealth() *Health {
+	hc := NewHealth(apptime.RealClock{}, time.Minute)
+	hc.MarkStarted()
+	return hc
+}
+
+func TestHealth_ReadinessFlipsWhenDraining(t *testing.T) {
+	hc := newStartedHealth()
+
+	rec := httptest.NewRecorder()
+	hc.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	hc.SetDraining(true)
+	rec = httptest.NewRecorder()
+	hc.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "draining")
+
+	hc.SetDraining(false)
+	rec = httptest.NewRecorder()
+	hc.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHealth_RejectWhileDraining(t *testing.T) {
+	hc := newStartedHealth()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", hc.Readyz)
+	mux.HandleFunc("GET /subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := hc.RejectWhileDraining(mux)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	hc.SetDraining(true)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "draining")
+}
+
+func TestHealth_LiveWaitsForStartup(t *testing.T) {
+	clock := apptime.NewFakeClock(time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC))
+	hc := NewHealth(clock, 30*time.Second)
+
+	probe := func(h http.HandlerFunc) int {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, probe(hc.Live))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(hc.Readyz))
+
+	hc.MarkStarted()
+	assert.Equal(t, http.StatusOK, probe(hc.Live))
+	assert.Equal(t, http.StatusOK, probe(hc.Readyz))
+}
+
+func TestHealth_LiveAssumesStartedAfterWindow(t *testing.T) {
+	clock := apptime.NewFakeClock(time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC))
+	hc := NewHealth(clock, 30*time.Second)
+
+	clock.Advance(29 * time.Second)
+	rec := httptest.NewRecorder()
+	hc.Live(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	clock.Advance(time.Second)
+	rec = httptest.NewRecorder()
+	hc.Live(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	hc.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "readiness still waits for real startup")
+}
@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"subscription-aggregator/internal/metrics"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+)
+
+const (
+	defaultImportMaxBytes = 10 << 20
+	maxReportedImportErrs = 100
+	importBatchSize       = 500
+)
+
+var requiredImportColumns = []string{"service_name", "price", "user_id", "start_date"}
+
+func WithImportMaxBytes(n int64) Option {
+	return func(h *SubscriptionHandler) {
+		h.importMaxBytes = n
+	}
+}
+
+// ImportSubscriptions stream-parses a CSV upload and creates one subscription
+// per row, so memory use does not grow with the file. Rows are committed in
+// batches as they are read: an upload cut off by the size limit keeps the
+// rows before it.
+// With upsert=true, rows matching an existing user/service/start_date update
+// that subscription instead of failing as duplicates. With async=true the
+// upload is buffered, answered with 202 and a job to poll, and imported in
+// the background.
+func (h *SubscriptionHandler) ImportSubscriptions(w http.ResponseWriter, r *http.Request) {
+	upsert, err := parseBool(r.URL.Query(), "upsert", false)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	async, err := parseBool(r.URL.Query(), "async", false)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if r.ContentLength > h.importMaxBytes {
+		http.Error(w, fmt.Sprintf(`{"error": "request body exceeds %d bytes"}`, h.importMaxBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var body io.Reader = http.MaxBytesReader(w, r.Body, h.importMaxBytes)
+	var started bool
+	if async {
+		// Reserve before buffering so a burst of uploads cannot pile up in memory.
+		if !h.importJobs.reserve() {
+			w.Header().Set("Retry-After", "10")
+			http.Error(w, `{"error": "too many import jobs running"}`, http.StatusServiceUnavailable)
+			return
+		}
+		defer func() {
+			if !started {
+				h.importJobs.release()
+			}
+		}()
+
+		buf, err := io.ReadAll(body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				http.Error(w, fmt.Sprintf(`{"error": "request body exceeds %d bytes"}`, h.importMaxBytes), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, `{"error": "failed to read upload"}`, http.StatusBadRequest)
+			return
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	cr := newImportReader(body)
+	header, err := cr.Read()
+	if err != nil {
+		if isBodyTooLarge(err) {
+			http.Error(w, fmt.Sprintf(`{"error": "request body exceeds %d bytes"}`, h.importMaxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, `{"error": "CSV header row is required"}`, http.StatusBadRequest)
+		return
+	}
+	columns, err := importColumns(header)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if async {
+		job := h.importJobs.start(h.clock.Now())
+		started = true
+		// The job outlives the request, so it must not inherit its cancellation.
+		h.importJobs.run(r.Context(), h.slowRouteTimeout, func(ctx context.Context) {
+			result, err := h.importRows(ctx, cr, columns, upsert)
+			h.importJobs.finish(job.ID, result, err, h.clock.Now())
+		})
+		w.Header().Set("Location", "/subscriptions/import/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	result, err := h.importRows(r.Context(), cr, columns, upsert)
+	if err != nil {
+		if isBodyTooLarge(err) {
+			msg := fmt.Sprintf("request body exceeds %d bytes", h.importMaxBytes)
+			http.Error(w, fmt.Sprintf(`{"error": %q, "created": %d, "updated": %d}`, msg, result.Created, result.Updated),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, `{"error": "failed to read upload"}`, http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func newImportReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cr.TrimLeadingSpace = true
+	return cr
+}
+
+// importRows saves every remaining record of cr. Row-level problems are
+// collected in the result; an error means the upload itself could not be
+// read to the end, and the result covers the rows before that point. Plain
+// imports are saved importBatchSize rows at a time with BulkInsert; upserts
+// go row by row, since COPY cannot update existing rows.
+func (h *SubscriptionHandler) importRows(ctx context.Context, cr *csv.Reader, columns map[string]int, upsert bool) (model.ImportResult, error) {
+	result := model.ImportResult{Errors: []model.ImportError{}}
+	fail := func(line int, err error) {
+		result.Failed++
+		if len(result.Errors) < maxReportedImportErrs {
+			result.Errors = append(result.Errors, model.ImportError{Line: line, Error: err.Error()})
+		}
+	}
+	save := func(line int, sub *model.Subscription) {
+		created := true
+		var err error
+		if upsert {
+			created, err = h.repo.UpsertByKey(ctx, sub)
+		} else {
+			err = h.repo.Create(ctx, sub)
+		}
+		if err != nil {
+			if errors.Is(err, repository.ErrDuplicate) || errors.Is(err, repository.ErrUserNotFound) ||
+				errors.Is(err, repository.ErrInvalidDateRange) {
+				fail(line, err)
+				return
+			}
+			slog.Error("Import row failed", "line", line, "error", err)
+			fail(line, errors.New("failed to save subscription"))
+			return
+		}
+		if created {
+			metrics.SubscriptionEvents.Inc(sub.ServiceName, metrics.EventCreated)
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	var pending []*model.Subscription
+	var pendingLines []int
+	// flush saves the pending rows and only fails when ctx has ended.
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		defer func() { pending, pendingLines = pending[:0], pendingLines[:0] }()
+		if err := h.repo.BulkInsert(ctx, pending); err == nil {
+			for _, sub := range pending {
+				metrics.SubscriptionEvents.Inc(sub.ServiceName, metrics.EventCreated)
+			}
+			result.Created += len(pending)
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// One bad row fails the whole batch, so save the rows one by one to
+		// report each problem against its own line.
+		for i, sub := range pending {
+			save(pendingLines[i], sub)
+		}
+		return nil
+	}
+	// Batched rows are saved after rows read later fail validation, so
+	// errors are put back in line order before returning.
+	finish := func(err error) (model.ImportResult, error) {
+		slices.SortStableFunc(result.Errors, func(a, b model.ImportError) int { return cmp.Compare(a.Line, b.Line) })
+		return result, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return finish(err)
+		}
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return finish(flush())
+		}
+		if err != nil {
+			if isBodyTooLarge(err) {
+				if flushErr := flush(); flushErr != nil {
+					return finish(flushErr)
+				}
+				return finish(err)
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				fail(parseErr.Line, parseErr.Err)
+				continue
+			}
+			slog.Error("Import read failed", "error", err)
+			if flushErr := flush(); flushErr != nil {
+				return finish(flushErr)
+			}
+			return finish(err)
+		}
+
+		line, _ := cr.FieldPos(0)
+		sub, err := parseImportRecord(record, columns)
+		if err == nil {
+			err = validateSubscription(sub)
+		}
+		if err != nil {
+			fail(line, err)
+			continue
+		}
+
+		if upsert {
+			save(line, sub)
+			continue
+		}
+		pending = append(pending, sub)
+		pendingLines = append(pendingLines, line)
+		if len(pending) == importBatchSize {
+			if err := flush(); err != nil {
+				return finish(err)
+			}
+		}
+	}
+}
+
+func isBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+func importColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	var missing []string
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing CSV columns: %s", strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+func parseImportRecord(record []string, columns map[string]int) (*model.Subscription, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	price, err := strconv.Atoi(field("price"))
+	if err != nil {
+		return nil, errors.New("price must be a positive integer")
+	}
+	start, err := monthdate.Parse(field("start_date"))
+	if err != nil {
+		return nil, errors.New("start_date must be in MM-YYYY format (e.g., 07-2025)")
+	}
+
+	sub := &model.Subscription{
+		ServiceName: field("service_name"),
+		Price:       price,
+		UserID:      field("user_id"),
+		StartDate:   start,
+	}
+	if v := field("end_date"); v != "" {
+		end, err := monthdate.Parse(v)
+		if err != nil {
+			return nil, errors.New("end_date must be in MM-YYYY format")
+		}
+		sub.EndDate = &end
+	}
+	return sub, nil
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// importJobTTL is how long a finished job stays available for polling.
+const importJobTTL = time.Hour
+
+const DefaultMaxImportJobs = 4
+
+// WithMaxImportJobs bounds how many async imports run at once. Each running
+// job holds its whole upload in memory.
+func WithMaxImportJobs(n int) Option {
+	return func(h *SubscriptionHandler) {
+		h.maxImportJobs = n
+	}
+}
+
+// importJobStore keeps async import jobs in memory. Jobs do not survive a
+// restart and are only visible on the instance that accepted the upload.
+type importJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*model.ImportJob
+
+	slots   chan struct{}
+	running sync.WaitGroup
+	// ctx is cancelled by drain to stop jobs that outlive the shutdown grace period.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newImportJobStore(maxRunning int) *importJobStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &importJobStore{
+		jobs:   make(map[string]*model.ImportJob),
+		slots:  make(chan struct{}, maxRunning),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// reserve claims a slot for a job, or reports false when all are taken. The
+// slot must be handed to run or given back with release.
+func (s *importJobStore) reserve() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *importJobStore) release() {
+	<-s.slots
+}
+
+// run starts fn in the background on a reserved slot. Its context keeps the
+// values of parent but is only cancelled by drain.
+func (s *importJobStore) run(parent context.Context, timeout time.Duration, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), timeout)
+	stop := context.AfterFunc(s.ctx, cancel)
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer s.release()
+		defer stop()
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// drain waits for running jobs. If ctx ends first, it cancels them, waits for
+// them to record their failure and returns ctx's error.
+func (s *importJobStore) drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *importJobStore) start(now time.Time) model.ImportJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now)
+	job := &model.ImportJob{ID: uuid.NewString(), Status: model.ImportJobRunning, CreatedAt: now}
+	s.jobs[job.ID] = job
+	return *job
+}
+
+// evict drops finished jobs past their TTL. Callers hold s.mu.
+func (s *importJobStore) evict(now time.Time) {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > importJobTTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func (s *importJobStore) finish(id string, result model.ImportResult, err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	job.Status = model.ImportJobCompleted
+	if err != nil {
+		job.Status = model.ImportJobFailed
+		job.Error = err.Error()
+	}
+	job.Result = &result
+	job.FinishedAt = &now
+}
+
+func (s *importJobStore) get(id string, now time.Time) (model.ImportJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evict(now)
+	job, ok := s.jobs[id]
+	if !ok {
+		return model.ImportJob{}, false
+	}
+	return *job, true
+}
+
+func (h *SubscriptionHandler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.importJobs.get(r.PathValue("job_id"), h.clock.Now())
+	if !ok {
+		http.Error(w, `{"error": "import job not found"}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// DrainImports waits for async imports to finish, cancelling any still
+// running when ctx ends. Call it on shutdown after the server stops accepting
+// requests.
+func (h *SubscriptionHandler) DrainImports(ctx context.Context) error {
+	return h.importJobs.drain(ctx)
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createCounter struct {
+	repository.SubscriptionRepository
+	created int
+	updated int
+	batches int
+	keys    map[string]bool
+}
+
+func subscriptionKey(sub *model.Subscription) string {
+	return sub.UserID + "|" + sub.ServiceName + "|" + sub.StartDate.String()
+}
+
+func (c *createCounter) Create(ctx context.Context, sub *model.Subscription) error {
+	key := subscriptionKey(sub)
+	if c.keys[key] {
+		return repository.ErrDuplicate
+	}
+	if c.keys == nil {
+		c.keys = make(map[string]bool)
+	}
+	c.keys[key] = true
+	c.created++
+	sub.ID = uuid.New().String()
+	return nil
+}
+
+// BulkInsert saves all subs or, like the database, none of them.
+func (c *createCounter) BulkInsert(ctx context.Context, subs []*model.Subscription) error {
+	c.batches++
+	seen := make(map[string]bool, len(subs))
+	for _, sub := range subs {
+		key := subscriptionKey(sub)
+		if c.keys[key] || seen[key] {
+			return repository.ErrDuplicate
+		}
+		seen[key] = true
+	}
+	for _, sub := range subs {
+		_ = c.Create(ctx, sub)
+	}
+	return nil
+}
+
+func (c *createCounter) UpsertByKey(ctx context.Context, sub *model.Subscription) (bool, error) {
+	err := c.Create(ctx, sub)
+	if errors.Is(err, repository.ErrDuplicate) {
+		c.updated++
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func importCSV(rows int) string {
+	var b strings.Builder
+	b.WriteString("service_name,price,user_id,start_date,end_date\n")
+	userID := uuid.New().String()
+	for i := 0; i < rows; i++ {
+		fmt.Fprintf(&b, "Service %d,%d,%s,01-2025,12-2025\n", i, 100+i, userID)
+	}
+	return b.String()
+}
+
+func TestImportSubscriptions_UnderLimit(t *testing.T) {
+	repo := &createCounter{}
+	h := NewSubscriptionHandler(repo, WithImportMaxBytes(1<<20))
+	body := importCSV(5000)
+	require.Less(t, len(body), 1<<20)
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 5000, repo.created)
+	assert.Contains(t, rec.Body.String(), `"created":5000`)
+}
+
+func TestImportSubscriptions_OverLimit(t *testing.T) {
+	body := importCSV(5000)
+
+	t.Run("declared length", func(t *testing.T) {
+		repo := &createCounter{}
+		h := NewSubscriptionHandler(repo, WithImportMaxBytes(64<<10))
+
+		rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Zero(t, repo.created)
+	})
+
+	t.Run("streamed without length", func(t *testing.T) {
+		repo := &createCounter{}
+		h := NewSubscriptionHandler(repo, WithImportMaxBytes(64<<10))
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions/import", io.MultiReader(strings.NewReader(body)))
+		req.ContentLength = -1
+
+		rec := serve(h, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.Less(t, repo.created, 5000)
+		assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"created": %d`, repo.created))
+	})
+}
+
+func TestImportSubscriptions_RowErrors(t *testing.T) {
+	repo := &createCounter{}
+	h := NewSubscriptionHandler(repo)
+	userID := uuid.New().String()
+	body := "service_name,price,user_id,start_date\n" +
+		"Spotify,300," + userID + ",07-2025\n" +
+		"Netflix,abc," + userID + ",07-2025\n" +
+		"Okko,400,not-a-uuid,07-2025\n"
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, repo.created)
+	assert.JSONEq(t, `{"created": 1, "updated": 0, "failed": 2, "errors": [
+		{"line": 3, "error": "price must be a positive integer"},
+		{"line": 4, "error": "user_id must be a valid UUID"}]}`, rec.Body.String())
+}
+
+func TestImportSubscriptions_Batches(t *testing.T) {
+	repo := &createCounter{}
+	h := NewSubscriptionHandler(repo)
+	userID := uuid.New().String()
+	var b strings.Builder
+	b.WriteString("service_name,price,user_id,start_date\n")
+	for i := 0; i < 1200; i++ {
+		fmt.Fprintf(&b, "Service %d,%d,%s,01-2025\n", i, 100+i, userID)
+		if i == 9 {
+			// Line 12 repeats line 5 and sinks the first batch.
+			fmt.Fprintf(&b, "Service 3,103,%s,01-2025\n", userID)
+		}
+	}
+	b.WriteString("Netflix,abc," + userID + ",07-2025\n")
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import", strings.NewReader(b.String())))
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, 1200, repo.created)
+	assert.Equal(t, 3, repo.batches, "rows are saved %d at a time", importBatchSize)
+	assert.JSONEq(t, `{"created": 1200, "updated": 0, "failed": 2, "errors": [
+		{"line": 12, "error": "subscription already exists"},
+		{"line": 1203, "error": "price must be a positive integer"}]}`, rec.Body.String())
+}
+
+func TestImportSubscriptions_MissingColumns(t *testing.T) {
+	h := NewSubscriptionHandler(&createCounter{})
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import", strings.NewReader("service_name,price\n")))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "missing CSV columns: user_id, start_date")
+}
+
+func TestImportSubscriptions_Duplicates(t *testing.T) {
+	userID := uuid.New().String()
+	body := "service_name,price,user_id,start_date\n" +
+		"Spotify,300," + userID + ",07-2025\n" +
+		"Netflix,800," + userID + ",07-2025\n" +
+		"Spotify,350," + userID + ",07-2025\n"
+
+	t.Run("rejected by default", func(t *testing.T) {
+		h := NewSubscriptionHandler(&createCounter{})
+
+		rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import", strings.NewReader(body)))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"created": 2, "updated": 0, "failed": 1, "errors": [
+			{"line": 4, "error": "subscription already exists"}]}`, rec.Body.String())
+	})
+
+	t.Run("updated with upsert", func(t *testing.T) {
+		h := NewSubscriptionHandler(&createCounter{})
+
+		rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import?upsert=true", strings.NewReader(body)))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"created": 2, "updated": 1, "failed": 0, "errors": []}`, rec.Body.String())
+	})
+
+	t.Run("invalid flag", func(t *testing.T) {
+		h := NewSubscriptionHandler(&createCounter{})
+
+		rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import?upsert=maybe", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestImportSubscriptions_Async(t *testing.T) {
+	repo := &createCounter{}
+	h := NewSubscriptionHandler(repo)
+	body := importCSV(200) + "Broken,abc," + uuid.NewString() + ",01-2025,\n"
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import?async=true", strings.NewReader(body)))
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+
+	var job model.ImportJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+	require.NotEmpty(t, job.ID)
+	location := rec.Header().Get("Location")
+	assert.Equal(t, "/subscriptions/import/jobs/"+job.ID, location)
+
+	require.Eventually(t, func() bool {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, location, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+		return job.Status != model.ImportJobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, model.ImportJobCompleted, job.Status)
+	require.NotNil(t, job.Result)
+	assert.Equal(t, 200, job.Result.Created)
+	assert.Equal(t, 1, job.Result.Failed)
+	assert.NotNil(t, job.FinishedAt)
+}
+
+// blockingCreator holds every Create until its context ends.
+type blockingCreator struct {
+	repository.SubscriptionRepository
+	started chan struct{}
+}
+
+func (b *blockingCreator) Create(ctx context.Context, sub *model.Subscription) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *blockingCreator) BulkInsert(ctx context.Context, subs []*model.Subscription) error {
+	return b.Create(ctx, nil)
+}
+
+func TestImportSubscriptions_AsyncJobLimitAndDrain(t *testing.T) {
+	repo := &blockingCreator{started: make(chan struct{}, 1)}
+	h := NewSubscriptionHandler(repo, WithMaxImportJobs(1))
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import?async=true", strings.NewReader(importCSV(3))))
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var job model.ImportJob
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+	<-repo.started
+
+	rec = serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import?async=true", strings.NewReader(importCSV(3))))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.DrainImports(ctx), context.DeadlineExceeded)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/import/jobs/"+job.ID, nil))
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&job))
+	assert.Equal(t, model.ImportJobFailed, job.Status, "a job cancelled at shutdown is recorded as failed")
+}
+
+func TestImportSubscriptions_AsyncRejectsBadUploadUpFront(t *testing.T) {
+	h := NewSubscriptionHandler(&createCounter{}, WithImportMaxBytes(1<<10))
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/import?async=true", strings.NewReader("name,price\n")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions/import?async=true", io.MultiReader(strings.NewReader(importCSV(100))))
+	req.ContentLength = -1
+	rec = serve(h, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/import/jobs/"+uuid.NewString(), nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+)
+
+func (h *SubscriptionHandler) GetNextInvoice(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.repo.ListByUserID(r.Context(), userID, repository.ListOptions{})
+	if err != nil {
+		slog.Error("Next invoice estimate failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to estimate next invoice")
+		return
+	}
+
+	next := monthdate.FromTime(h.clock.Now()).AddMonths(1)
+	writeJSON(w, http.StatusOK, estimateInvoice(subs, next))
+}
+
+// estimateInvoice totals the subscriptions billed in month, one line per
+// service. Subscriptions have no billing cycle or day, so every subscription
+// active in month bills its full monthly price once.
+func estimateInvoice(subs []model.Subscription, month monthdate.MonthDate) model.InvoiceEstimate {
+	byService := make(map[string]*model.InvoiceItem)
+	for _, sub := range subs {
+		if sub.StartDate.After(month) || (sub.EndDate != nil && sub.EndDate.Before(month)) {
+			continue
+		}
+		item, ok := byService[sub.ServiceName]
+		if !ok {
+			item = &model.InvoiceItem{ServiceName: sub.ServiceName}
+			byService[sub.ServiceName] = item
+		}
+		item.Subscriptions++
+		item.Amount += sub.Price
+	}
+
+	invoice := model.InvoiceEstimate{Month: month, Items: make([]model.InvoiceItem, 0, len(byService))}
+	for _, item := range byService {
+		invoice.Items = append(invoice.Items, *item)
+		invoice.Total += item.Amount
+	}
+	sort.Slice(invoice.Items, func(i, j int) bool {
+		return invoice.Items[i].ServiceName < invoice.Items[j].ServiceName
+	})
+	return invoice
+}
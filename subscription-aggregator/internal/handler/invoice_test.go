@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNextInvoice_FixedClock(t *testing.T) {
+	endsNovember := monthdate.New(2025, time.November)
+	endsDecember := monthdate.New(2025, time.December)
+	repo := listRepo{subs: []model.Subscription{
+		{ID: "1", ServiceName: "Spotify", Price: 300, StartDate: monthdate.New(2024, time.January)},
+		{ID: "2", ServiceName: "Netflix", Price: 800, StartDate: monthdate.New(2025, time.March), EndDate: &endsDecember},
+		{ID: "3", ServiceName: "Netflix", Price: 500, StartDate: monthdate.New(2025, time.December)},
+		{ID: "4", ServiceName: "Okko", Price: 400, StartDate: monthdate.New(2025, time.January), EndDate: &endsNovember},
+		{ID: "5", ServiceName: "ivi", Price: 200, StartDate: monthdate.New(2026, time.January)},
+	}}
+	clock := apptime.NewFakeClock(time.Date(2025, time.November, 30, 23, 0, 0, 0, time.UTC))
+	h := NewSubscriptionHandler(repo, WithClock(clock))
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/next-invoice?user_id="+uuid.New().String(), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var invoice model.InvoiceEstimate
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&invoice))
+	assert.Equal(t, model.InvoiceEstimate{
+		Month: monthdate.New(2025, time.December),
+		Total: 1600,
+		Items: []model.InvoiceItem{
+			{ServiceName: "Netflix", Subscriptions: 2, Amount: 1300},
+			{ServiceName: "Spotify", Subscriptions: 1, Amount: 300},
+		},
+	}, invoice)
+}
+
+func TestGetNextInvoice_InvalidUserID(t *testing.T) {
+	h := NewSubscriptionHandler(listRepo{})
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/next-invoice?user_id=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/next-invoice", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/next-invoice?user_id="+uuid.Nil.String(), nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
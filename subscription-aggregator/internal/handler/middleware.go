@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h so that middlewares run in the order given: the first one
+// sees the request first and the response last.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// ServerMiddleware is the server-wide chain in execution order. Security
+// headers come first so they are also set on responses rejected further in,
+// and the drain check runs before a concurrency slot is taken. Compression
+// is last so it only spends CPU on requests that reach a handler.
+func ServerMiddleware(httpsOnly bool, health *Health, maxConcurrent int, gzip Middleware) []Middleware {
+	return []Middleware{
+		SecurityHeadersMiddleware(httpsOnly),
+		health.RejectWhileDraining,
+		ConcurrencyLimitMiddleware(maxConcurrent),
+		gzip,
+	}
+}
+
+const DefaultMaxConcurrentRequests = 100
+
+// ConcurrencyLimitMiddleware allows at most max requests in flight and answers
+// the rest with 503 straight away instead of queueing them. Probes are not
+// counted so a burst cannot fail liveness checks.
+func ConcurrencyLimitMiddleware(max int) Middleware {
+	sem := make(chan struct{}, max)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isProbe(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, `{"error": "too many concurrent requests"}`, http.StatusServiceUnavailable)
+				return
+			}
+			defer func() { <-sem }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+const (
+	defaultRouteTimeout     = 10 * time.Second
+	defaultSlowRouteTimeout = 2 * time.Minute
+)
+
+// WithRouteTimeouts sets the request deadline for regular routes and for the
+// routes registered as slow (exports, imports, long-range aggregates).
+func WithRouteTimeouts(route, slow time.Duration) Option {
+	return func(h *SubscriptionHandler) {
+		h.routeTimeout = route
+		h.slowRouteTimeout = slow
+	}
+}
+
+// timeout bounds the request context of a single route, so each route can be
+// given its own deadline when it is registered.
+func (h *SubscriptionHandler) timeout(d time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		next(w, r.WithContext(ctx))
+	}
+}
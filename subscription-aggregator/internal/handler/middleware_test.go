@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serverChain wraps final in the production middleware chain, compressing
+// every response so that compression is observable on small bodies.
+func serverChain(t *testing.T, health *Health, maxConcurrent int, final http.Handler) http.Handler {
+	t.Helper()
+	gz, err := GzipMiddleware(gzip.BestSpeed, 0)
+	require.NoError(t, err)
+	return Chain(final, ServerMiddleware(false, health, maxConcurrent, gz)...)
+}
+
+func TestMiddlewareExecutionOrder(t *testing.T) {
+	health := NewHealth(apptime.NewFakeClock(time.Now()), 0)
+	var calls []string
+	entered, release := make(chan struct{}), make(chan struct{})
+	h := serverChain(t, health, 1, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+		assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"), "security headers run before the handler")
+		assert.IsType(t, &gzipResponseWriter{}, w, "compression wraps the handler directly")
+		if r.URL.Path == "/slow" {
+			close(entered)
+			<-release
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/subscriptions")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, []string{"handler"}, calls)
+
+	// Fill the only concurrency slot.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		get("/slow")
+	}()
+	<-entered
+
+	// Draining is checked before the concurrency limit...
+	health.SetDraining(true)
+	rec = get("/subscriptions")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "server is shutting down")
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+
+	// ...and the limit before compression, so rejections are sent as-is.
+	health.SetDraining(false)
+	rec = get("/subscriptions")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "too many concurrent requests")
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	close(release)
+	<-done
+	assert.Equal(t, []string{"handler", "handler"}, calls, "rejected requests never reach the handler")
+}
+
+func TestServerMiddleware_SecurityHeadersOnRejectedRequests(t *testing.T) {
+	health := NewHealth(apptime.NewFakeClock(time.Now()), 0)
+	health.SetDraining(true)
+	h := serverChain(t, health, DefaultMaxConcurrentRequests, http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"), "security headers must wrap the drain check")
+}
+
+func TestConcurrencyLimitMiddleware(t *testing.T) {
+	const max, total = 10, 200
+	var inFlight, peak atomic.Int32
+	release := make(chan struct{})
+	h := ConcurrencyLimitMiddleware(max)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		inFlight.Add(-1)
+	}))
+
+	codes := make(chan int, total)
+	for range total {
+		go func() {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+			codes <- rec.Code
+		}()
+	}
+
+	// Admitted requests block until release, so every other one must have
+	// been turned away before any slot frees up.
+	for range total - max {
+		assert.Equal(t, http.StatusServiceUnavailable, <-codes)
+	}
+	close(release)
+	for range max {
+		assert.Equal(t, http.StatusOK, <-codes)
+	}
+	assert.Equal(t, int32(max), peak.Load())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "slots are released after each request")
+}
+
+// deadlineRepo records the context deadline of the last repository call.
+type deadlineRepo struct {
+	repository.SubscriptionRepository
+	remaining time.Duration
+}
+
+func (d *deadlineRepo) record(ctx context.Context) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return errors.New("no deadline")
+	}
+	d.remaining = time.Until(deadline)
+	return repository.ErrNotFound
+}
+
+func (d *deadlineRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
+	return nil, d.record(ctx)
+}
+
+func (d *deadlineRepo) ListByUserID(ctx context.Context, userID string, opts repository.ListOptions) ([]model.Subscription, error) {
+	return nil, d.record(ctx)
+}
+
+func TestRouteTimeouts_ExportOutlastsGet(t *testing.T) {
+	repo := &deadlineRepo{}
+	h := NewSubscriptionHandler(repo, WithRouteTimeouts(5*time.Second, time.Minute))
+
+	serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/"+uuid.New().String(), nil))
+	getDeadline := repo.remaining
+	serve(h, httptest.NewRequest(http.MethodGet, "/users/"+uuid.New().String()+"/export", nil))
+	exportDeadline := repo.remaining
+
+	assert.InDelta(t, 5*time.Second, getDeadline, float64(time.Second))
+	assert.InDelta(t, time.Minute, exportDeadline, float64(time.Second))
+	assert.Greater(t, exportDeadline, getDeadline)
+}
+
+func TestGzipMiddleware_Threshold(t *testing.T) {
+	const minBytes = 100
+	mw, err := GzipMiddleware(gzip.BestSpeed, minBytes)
+	assert.NoError(t, err)
+
+	respond := func(size int, acceptEncoding string) *httptest.ResponseRecorder {
+		h := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(strings.Repeat("a", size)))
+		}), mw)
+		req := httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := respond(minBytes-1, "gzip")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"), "responses under the threshold are not compressed")
+	assert.Equal(t, strings.Repeat("a", minBytes-1), rec.Body.String())
+
+	rec = respond(minBytes, "deflate, gzip")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	zr, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("a", minBytes), string(body))
+
+	rec = respond(minBytes, "gzip;q=0")
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	for _, level := range []int{gzip.HuffmanOnly - 1, gzip.BestCompression + 1} {
+		_, err := GzipMiddleware(level, minBytes)
+		assert.Error(t, err, level)
+	}
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+
+	defaultCursorLimit = 20
+	maxCursorLimit     = 100
+)
+
+// metadataParamPrefix marks list filters on top-level metadata keys,
+// e.g. ?meta.external_id=abc.
+const metadataParamPrefix = "meta."
+
+type ListParams struct {
+	UserID        string
+	ServiceName   string
+	CaseSensitive bool
+	Metadata      map[string]string
+	ActiveOn      string
+	// IncludeDeleted also lists soft-deleted subscriptions; admin only.
+	IncludeDeleted bool
+	Sort           string
+	Limit          int
+	Offset         int
+}
+
+func (p ListParams) ListOptions() repository.ListOptions {
+	return repository.ListOptions{
+		ServiceName:    p.ServiceName,
+		CaseSensitive:  p.CaseSensitive,
+		Metadata:       p.Metadata,
+		ActiveOn:       p.ActiveOn,
+		IncludeDeleted: p.IncludeDeleted,
+		Sort:           p.Sort,
+		Limit:          p.Limit,
+		Offset:         p.Offset,
+	}
+}
+
+// CursorParams select a keyset page of a user's subscriptions; After is the
+// decoded cursor of the previous page, nil for the first one.
+type CursorParams struct {
+	UserID string
+	After  *repository.Cursor
+	Limit  int
+}
+
+type paramErrors []string
+
+func (e paramErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+// knownParams rejects requests carrying query parameters outside known when
+// strict query mode is enabled; otherwise unknown parameters are ignored.
+// A known entry ending in "." admits any parameter with that prefix.
+func (h *SubscriptionHandler) knownParams(next http.HandlerFunc, known ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.strictQuery {
+			if unknown := unknownParams(r, known); len(unknown) > 0 {
+				msg := "unknown query parameters: " + strings.Join(unknown, ", ")
+				http.Error(w, fmt.Sprintf(`{"error": %q}`, msg), http.StatusBadRequest)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+func unknownParams(r *http.Request, known []string) []string {
+	var unknown []string
+	for name := range r.URL.Query() {
+		if !slices.ContainsFunc(known, func(k string) bool {
+			return k == name || (strings.HasSuffix(k, ".") && strings.HasPrefix(name, k))
+		}) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// parseUserID reads the required user_id query parameter, which like any
+// stored user_id must be a UUID other than the nil UUID.
+func parseUserID(query url.Values) (string, error) {
+	userID := query.Get("user_id")
+	if userID == "" {
+		return "", errors.New("user_id query parameter is required")
+	}
+	if err := validateUserID(userID); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// parsePage reads the limit and offset of an offset-paginated list.
+func parsePage(query url.Values, defaultLimit, maxLimit int) (limit, offset int, errs paramErrors) {
+	limit = defaultLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		switch {
+		case err != nil:
+			errs = append(errs, "limit must be an integer")
+		case n < 1 || n > maxLimit:
+			errs = append(errs, "limit must be between 1 and "+strconv.Itoa(maxLimit))
+		default:
+			limit = n
+		}
+	}
+
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		switch {
+		case err != nil:
+			errs = append(errs, "offset must be an integer")
+		case n < 0:
+			errs = append(errs, "offset must not be negative")
+		default:
+			offset = n
+		}
+	}
+	return limit, offset, errs
+}
+
+// parseIntParam reads an optional integer query parameter that must lie in
+// min..max. A missing or empty parameter yields def.
+func parseIntParam(query url.Values, name string, def, min, max int) (int, error) {
+	v := query.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return def, fmt.Errorf("%s must be an integer between %d and %d", name, min, max)
+	}
+	return n, nil
+}
+
+// paginate returns the page of all selected by limit and offset, for list
+// endpoints whose queries return every match.
+func paginate[T any](all []T, limit, offset int) model.PaginatedResponse[T] {
+	start := min(offset, len(all))
+	end := min(start+limit, len(all))
+	return model.NewPaginatedResponse(all[start:end], len(all), limit, offset)
+}
+
+// parseBool reads an optional boolean query flag, accepting true/false, 1/0
+// and yes/no in any case. A missing or empty flag yields def.
+func parseBool(query url.Values, name string, def bool) (bool, error) {
+	v := strings.TrimSpace(query.Get(name))
+	if v == "" {
+		return def, nil
+	}
+	switch strings.ToLower(v) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	}
+	return def, fmt.Errorf("%s must be a boolean (true/false, 1/0, yes/no)", name)
+}
+
+func parseListParams(r *http.Request) (ListParams, error) {
+	q := r.URL.Query()
+	var errs paramErrors
+
+	params := ListParams{
+		UserID:      q.Get("user_id"),
+		ServiceName: q.Get("service_name"),
+		Limit:       defaultListLimit,
+	}
+
+	if _, err := parseUserID(q); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	var pageErrs paramErrors
+	params.Limit, params.Offset, pageErrs = parsePage(q, defaultListLimit, maxListLimit)
+	errs = append(errs, pageErrs...)
+
+	if v := q.Get("active_on"); v != "" {
+		if err := ValidatePeriodDate(v); err != nil {
+			errs = append(errs, "invalid active_on: "+err.Error())
+		} else {
+			params.ActiveOn = v
+		}
+	}
+
+	if v := q.Get("sort"); v != "" {
+		if sorts := repository.ListSorts(); slices.Contains(sorts, v) {
+			params.Sort = v
+		} else {
+			errs = append(errs, "sort must be one of: "+strings.Join(sorts, ", "))
+		}
+	}
+
+	caseSensitive, err := parseBool(q, "case_sensitive", false)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	params.CaseSensitive = caseSensitive
+
+	includeDeleted, err := parseBool(q, "include_deleted", false)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	params.IncludeDeleted = includeDeleted
+
+	for name, values := range q {
+		key, ok := strings.CutPrefix(name, metadataParamPrefix)
+		if !ok {
+			continue
+		}
+		if key == "" {
+			errs = append(errs, "metadata filter key must not be empty")
+			continue
+		}
+		if params.Metadata == nil {
+			params.Metadata = make(map[string]string)
+		}
+		params.Metadata[key] = values[0]
+	}
+
+	if len(errs) > 0 {
+		return ListParams{}, errs
+	}
+	return params, nil
+}
+
+// parseCursorParams reads ?after=<cursor>&limit=<n>. Cursor pages follow
+// their own (start_date, id) order, so the filters and offset of offset
+// pagination do not apply.
+func parseCursorParams(r *http.Request) (CursorParams, error) {
+	q := r.URL.Query()
+	var errs paramErrors
+
+	params := CursorParams{UserID: q.Get("user_id"), Limit: defaultCursorLimit}
+
+	if _, err := parseUserID(q); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if v := q.Get("after"); v != "" {
+		after, err := repository.DecodeCursor(v)
+		if err != nil {
+			errs = append(errs, "after must be a cursor returned as next_cursor")
+		}
+		params.After = &after
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		switch {
+		case err != nil:
+			errs = append(errs, "limit must be an integer")
+		case limit < 1 || limit > maxCursorLimit:
+			errs = append(errs, "limit must be between 1 and "+strconv.Itoa(maxCursorLimit))
+		default:
+			params.Limit = limit
+		}
+	}
+
+	var conflicting []string
+	for name := range q {
+		switch {
+		case name == "offset", name == "sort", name == "service_name", name == "case_sensitive", name == "active_on", name == "include_deleted",
+			strings.HasPrefix(name, metadataParamPrefix):
+			conflicting = append(conflicting, name)
+		}
+	}
+	sort.Strings(conflicting)
+	for _, name := range conflicting {
+		errs = append(errs, name+" cannot be combined with after")
+	}
+
+	if len(errs) > 0 {
+		return CursorParams{}, errs
+	}
+	return params, nil
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListParams_Valid(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name  string
+		query string
+		want  ListParams
+	}{
+		{"defaults", "user_id=" + userID, ListParams{UserID: userID, Limit: defaultListLimit}},
+		{"service filter", "user_id=" + userID + "&service_name=Spotify", ListParams{UserID: userID, ServiceName: "Spotify", Limit: defaultListLimit}},
+		{"limit and offset", "user_id=" + userID + "&limit=10&offset=30", ListParams{UserID: userID, Limit: 10, Offset: 30}},
+		{"max limit", "user_id=" + userID + "&limit=200", ListParams{UserID: userID, Limit: 200}},
+		{"case sensitive", "user_id=" + userID + "&case_sensitive=true", ListParams{UserID: userID, CaseSensitive: true, Limit: defaultListLimit}},
+		{"active on", "user_id=" + userID + "&active_on=05-2025", ListParams{UserID: userID, ActiveOn: "05-2025", Limit: defaultListLimit}},
+		{"sort", "user_id=" + userID + "&sort=price_desc", ListParams{UserID: userID, Sort: "price_desc", Limit: defaultListLimit}},
+		{"metadata filter", "user_id=" + userID + "&meta.external_id=ext-1&meta.tier=gold", ListParams{UserID: userID,
+			Metadata: map[string]string{"external_id": "ext-1", "tier": "gold"}, Limit: defaultListLimit}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/subscriptions?"+tt.query, nil)
+			got, err := parseListParams(r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseListParams_Invalid(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"missing user_id", "", "user_id query parameter is required"},
+		{"invalid user_id", "user_id=abc", "user_id must be a valid UUID"},
+		{"nil user_id", "user_id=" + uuid.Nil.String(), "user_id must not be the nil UUID"},
+		{"non-numeric limit", "user_id=" + userID + "&limit=ten", "limit must be an integer"},
+		{"zero limit", "user_id=" + userID + "&limit=0", "limit must be between 1 and 200"},
+		{"limit too large", "user_id=" + userID + "&limit=201", "limit must be between 1 and 200"},
+		{"non-numeric offset", "user_id=" + userID + "&offset=x", "offset must be an integer"},
+		{"negative offset", "user_id=" + userID + "&offset=-1", "offset must not be negative"},
+		{"invalid case_sensitive", "user_id=" + userID + "&case_sensitive=maybe", "case_sensitive must be a boolean (true/false, 1/0, yes/no)"},
+		{"invalid active_on", "user_id=" + userID + "&active_on=2025-05", "invalid active_on: date must be in MM-YYYY format"},
+		{"active_on year out of range", "user_id=" + userID + "&active_on=05-0001", "invalid active_on: date must be in MM-YYYY format"},
+		{"empty metadata key", "user_id=" + userID + "&meta.=x", "metadata filter key must not be empty"},
+		{"unknown sort", "user_id=" + userID + "&sort=price", "sort must be one of: created_at_asc, created_at_desc, price_asc, price_desc, service_name_asc, start_date_asc, start_date_desc"},
+		{"sort injection", "user_id=" + userID + "&sort=price%3BDROP%20TABLE%20subscriptions", "sort must be one of: created_at_asc, created_at_desc, price_asc, price_desc, service_name_asc, start_date_asc, start_date_desc"},
+		{"aggregated", "limit=-5&offset=-1", "user_id query parameter is required; limit must be between 1 and 200; offset must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/subscriptions?"+tt.query, nil)
+			_, err := parseListParams(r)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestKnownParams_StrictRejectsUnknown(t *testing.T) {
+	h := NewSubscriptionHandler(nil, WithStrictQueryParams(true))
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	rec := httptest.NewRecorder()
+	h.knownParams(next, "user_id", "limit")(rec, httptest.NewRequest("GET", "/subscriptions?usr_id=1&limit=5&foo=bar", nil))
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown query parameters: foo, usr_id")
+}
+
+func TestKnownParams_PrefixAllowsMetadataFilters(t *testing.T) {
+	h := NewSubscriptionHandler(nil, WithStrictQueryParams(true))
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	rec := httptest.NewRecorder()
+	h.knownParams(next, "user_id", metadataParamPrefix)(rec, httptest.NewRequest("GET", "/subscriptions?user_id=1&meta.external_id=x", nil))
+	assert.True(t, called)
+
+	called = false
+	rec = httptest.NewRecorder()
+	h.knownParams(next, "user_id", metadataParamPrefix)(rec, httptest.NewRequest("GET", "/subscriptions?user_id=1&metadata=x", nil))
+	assert.False(t, called)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestKnownParams_LenientIgnoresUnknown(t *testing.T) {
+	h := NewSubscriptionHandler(nil)
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) { called = true }
+
+	rec := httptest.NewRecorder()
+	h.knownParams(next, "user_id")(rec, httptest.NewRequest("GET", "/subscriptions?usr_id=1", nil))
+
+	assert.True(t, called)
+}
+
+func TestParseBool(t *testing.T) {
+	for _, v := range []string{"true", "TRUE", "True", "1", "yes", "YES", " yes "} {
+		got, err := parseBool(url.Values{"flag": {v}}, "flag", false)
+		require.NoError(t, err, v)
+		assert.True(t, got, v)
+	}
+	for _, v := range []string{"false", "FALSE", "0", "no", "No"} {
+		got, err := parseBool(url.Values{"flag": {v}}, "flag", true)
+		require.NoError(t, err, v)
+		assert.False(t, got, v)
+	}
+	for _, v := range []string{"maybe", "2", "on", "off", "t", "y", "-1"} {
+		_, err := parseBool(url.Values{"flag": {v}}, "flag", false)
+		require.Error(t, err, v)
+		assert.Equal(t, "flag must be a boolean (true/false, 1/0, yes/no)", err.Error())
+	}
+
+	got, err := parseBool(url.Values{}, "flag", true)
+	require.NoError(t, err)
+	assert.True(t, got, "missing flag falls back to the default")
+	got, err = parseBool(url.Values{"flag": {""}}, "flag", true)
+	require.NoError(t, err)
+	assert.True(t, got, "empty flag falls back to the default")
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+)
+
+const projectionMonths = 12
+
+func (h *SubscriptionHandler) GetProjectedAnnual(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseUserID(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.repo.ListByUserID(r.Context(), userID, repository.ListOptions{})
+	if err != nil {
+		slog.Error("Projected annual cost failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to calculate projected annual cost")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, projectAnnualCost(subs, monthdate.FromTime(h.clock.Now())))
+}
+
+// projectAnnualCost sums the next twelve months, starting with current, of
+// every subscription active in current. Subscriptions ending within that
+// window only count their remaining months.
+func projectAnnualCost(subs []model.Subscription, current monthdate.MonthDate) model.AnnualProjection {
+	last := current.AddMonths(projectionMonths - 1)
+	result := model.AnnualProjection{
+		From:          current,
+		To:            last,
+		Subscriptions: []model.ProjectedSubscription{},
+	}
+
+	for _, sub := range subs {
+		if sub.StartDate.After(current) || (sub.EndDate != nil && sub.EndDate.Before(current)) {
+			continue
+		}
+
+		months := projectionMonths
+		if sub.EndDate != nil && sub.EndDate.Before(last) {
+			months = monthdate.MonthsBetween(current, *sub.EndDate)
+		}
+
+		cost := sub.Price * months
+		result.ProjectedAnnualCost += cost
+		result.Subscriptions = append(result.Subscriptions, model.ProjectedSubscription{
+			ID:            sub.ID,
+			ServiceName:   sub.ServiceName,
+			MonthlyPrice:  sub.Price,
+			Months:        months,
+			ProjectedCost: cost,
+		})
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectAnnualCost(t *testing.T) {
+	current := monthdate.New(2025, time.October)
+	endsMarch := monthdate.New(2026, time.March)
+	endedLastMonth := monthdate.New(2025, time.September)
+	endsLater := monthdate.New(2027, time.January)
+
+	subs := []model.Subscription{
+		{ID: "open", ServiceName: "Spotify", Price: 300, StartDate: monthdate.New(2024, time.January)},
+		{ID: "expiring", ServiceName: "Netflix", Price: 800, StartDate: monthdate.New(2025, time.January), EndDate: &endsMarch},
+		{ID: "ended", ServiceName: "Okko", Price: 400, StartDate: monthdate.New(2025, time.January), EndDate: &endedLastMonth},
+		{ID: "future", ServiceName: "ivi", Price: 200, StartDate: monthdate.New(2025, time.November)},
+		{ID: "long", ServiceName: "iCloud+", Price: 150, StartDate: monthdate.New(2025, time.October), EndDate: &endsLater},
+	}
+
+	result := projectAnnualCost(subs, current)
+
+	assert.Equal(t, current, result.From)
+	assert.Equal(t, monthdate.New(2026, time.September), result.To)
+	require.Len(t, result.Subscriptions, 3)
+	assert.Equal(t, model.ProjectedSubscription{ID: "open", ServiceName: "Spotify", MonthlyPrice: 300, Months: 12, ProjectedCost: 3600}, result.Subscriptions[0])
+	assert.Equal(t, model.ProjectedSubscription{ID: "expiring", ServiceName: "Netflix", MonthlyPrice: 800, Months: 6, ProjectedCost: 4800}, result.Subscriptions[1])
+	assert.Equal(t, 12, result.Subscriptions[2].Months)
+	assert.Equal(t, 3600+4800+1800, result.ProjectedAnnualCost)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// GetCostReport godoc
+// @Summary      Get a cost report
+// @Description  Total cost of a user's subscriptions for the from..to period, broken down by service. format selects JSON (default), CSV or PDF output.
+// @Tags         subscriptions
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/pdf
+// @Param        user_id  query     string  true   "User ID (UUID)"
+// @Param        from     query     string  true   "Period start (MM-YYYY)"
+// @Param        to       query     string  true   "Period end (MM-YYYY)"
+// @Param        format   query     string  false  "Output format"  Enums(json, csv, pdf)  default(json)
+// @Success      200      {object}  model.CostReport
+// @Failure      400      {object}  model.ErrorResponse  "Invalid parameters"
+// @Failure      500      {object}  model.ErrorResponse
+// @Router       /subscriptions/report [get]
+func (h *SubscriptionHandler) GetCostReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var errs paramErrors
+	userID, err := parseUserID(q)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	from, to := q.Get("from"), q.Get("to")
+	if err := h.validatePeriod(from, to); err != nil {
+		errs = append(errs, err.Error())
+	}
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" && format != "pdf" {
+		errs = append(errs, "format must be one of: json, csv, pdf")
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.repo.TotalCost(r.Context(), repository.CostFilter{UserID: userID, From: from, To: to, Itemize: true})
+	if err != nil {
+		slog.Error("Cost report failed", "user_id", userID, "error", err)
+		writeRepoError(w, err, "failed to build cost report")
+		return
+	}
+	// Both dates were validated above.
+	fromDate, _ := monthdate.Parse(from)
+	toDate, _ := monthdate.Parse(to)
+	report := costReport(userID, fromDate, toDate, summary)
+
+	switch format {
+	case "csv":
+		writeReportFile(w, userID, "text/csv", "csv", reportCSV(report))
+	case "pdf":
+		body, err := reportPDF(report)
+		if err != nil {
+			slog.Error("Cost report PDF rendering failed", "user_id", userID, "error", err)
+			http.Error(w, `{"error": "failed to build cost report"}`, http.StatusInternalServerError)
+			return
+		}
+		writeReportFile(w, userID, "application/pdf", "pdf", body)
+	default:
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// costReport groups the itemized summary by service, ordered by name.
+func costReport(userID string, from, to monthdate.MonthDate, summary model.CostSummary) model.CostReport {
+	byService := make(map[string]*model.ServiceCost)
+	for _, item := range summary.Items {
+		svc, ok := byService[item.ServiceName]
+		if !ok {
+			svc = &model.ServiceCost{ServiceName: item.ServiceName}
+			byService[item.ServiceName] = svc
+		}
+		svc.Subscriptions++
+		svc.Amount += item.Contribution
+	}
+
+	report := model.CostReport{UserID: userID, From: from, To: to, Total: summary.Total,
+		Services: make([]model.ServiceCost, 0, len(byService))}
+	for _, svc := range byService {
+		report.Services = append(report.Services, *svc)
+	}
+	sort.Slice(report.Services, func(i, j int) bool {
+		return report.Services[i].ServiceName < report.Services[j].ServiceName
+	})
+	return report
+}
+
+func writeReportFile(w http.ResponseWriter, userID, contentType, ext string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="report-%s.%s"`, userID, ext))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		slog.Error("Failed to write cost report", "user_id", userID, "error", err)
+	}
+}
+
+// reportCSV writes one row per service followed by a total row.
+func reportCSV(report model.CostReport) []byte {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	cw.Write([]string{"service_name", "subscriptions", "amount"})
+	for _, svc := range report.Services {
+		cw.Write([]string{svc.ServiceName, strconv.Itoa(svc.Subscriptions), strconv.Itoa(svc.Amount)})
+	}
+	cw.Write([]string{"total", "", strconv.Itoa(report.Total)})
+	cw.Flush()
+	return buf.Bytes()
+}
+
+func reportPDF(report model.CostReport) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	// The core fonts only cover cp1252; service names may use anything else.
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Cell(0, 10, "Subscription cost report")
+	pdf.Ln(12)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.Cell(0, 6, "User: "+report.UserID)
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Period: %s to %s", report.From, report.To))
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(110, 8, "Service", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(35, 8, "Subscriptions", "B", 0, "R", false, 0, "")
+	pdf.CellFormat(35, 8, "Amount", "B", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	for _, svc := range report.Services {
+		pdf.CellFormat(110, 7, tr(svc.ServiceName), "", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 7, strconv.Itoa(svc.Subscriptions), "", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 7, strconv.Itoa(svc.Amount), "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(145, 8, "Total", "T", 0, "L", false, 0, "")
+	pdf.CellFormat(35, 8, strconv.Itoa(report.Total), "T", 1, "R", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
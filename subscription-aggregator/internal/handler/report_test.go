@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type costRepo struct {
+	repository.SubscriptionRepository
+	summary model.CostSummary
+}
+
+func (c costRepo) TotalCost(ctx context.Context, f repository.CostFilter) (model.CostSummary, error) {
+	return c.summary, nil
+}
+
+func TestGetCostReport(t *testing.T) {
+	h := NewSubscriptionHandler(costRepo{summary: model.CostSummary{Total: 4800, Count: 3, Matched: true, Items: []model.CostItem{
+		{ID: uuid.NewString(), ServiceName: "Spotify", Contribution: 1200},
+		{ID: uuid.NewString(), ServiceName: "Netflix", Contribution: 2400},
+		{ID: uuid.NewString(), ServiceName: "Spotify", Contribution: 1200},
+	}}})
+	base := "/subscriptions/report?user_id=" + uuid.NewString() + "&from=01-2025&to=12-2025"
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, base, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report model.CostReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 4800, report.Total)
+	assert.Equal(t, "01-2025", report.From.String())
+	assert.Equal(t, []model.ServiceCost{
+		{ServiceName: "Netflix", Subscriptions: 1, Amount: 2400},
+		{ServiceName: "Spotify", Subscriptions: 2, Amount: 2400},
+	}, report.Services)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, base+"&format=csv", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "service_name,subscriptions,amount\nNetflix,1,2400\nSpotify,2,2400\ntotal,,4800\n", rec.Body.String())
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, base+"&format=pdf", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.True(t, bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")), "body is a PDF document")
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, base+"&format=xlsx", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/report?user_id="+uuid.NewString()+"&from=01-2025", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/report?user_id="+uuid.Nil.String()+"&from=01-2025&format=xlsx", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error": "user_id must not be the nil UUID; 'from' and 'to' query parameters are required; format must be one of: json, csv, pdf"}`, rec.Body.String())
+}
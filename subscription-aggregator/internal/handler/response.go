@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"subscription-aggregator/internal/repository"
+)
+
+var responseWriteTimeout = 10 * time.Second
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(responseWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Warn("Failed to set response write deadline", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write response", "error", err)
+	}
+}
+
+const (
+	returnMinimal        = "minimal"
+	returnRepresentation = "representation"
+)
+
+// preferReturn reports the RFC 7240 "return" preference of the request,
+// defaulting to representation when the client expresses none.
+func preferReturn(r *http.Request) string {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "return") &&
+				strings.EqualFold(strings.Trim(strings.TrimSpace(value), `"`), returnMinimal) {
+				return returnMinimal
+			}
+		}
+	}
+	return returnRepresentation
+}
+
+// writeMutation writes the result of a POST/PUT/PATCH, honouring
+// Prefer: return=minimal with an empty 204.
+func writeMutation(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if preferReturn(r) == returnMinimal {
+		w.Header().Set("Preference-Applied", "return=minimal")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, status, v)
+}
+
+func writeRepoError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
+	case errors.Is(err, repository.ErrDuplicate):
+		http.Error(w, `{"error": "subscription already exists"}`, http.StatusConflict)
+	case errors.Is(err, repository.ErrIDTaken):
+		http.Error(w, `{"error": "subscription id belongs to a deleted or another user's subscription"}`, http.StatusConflict)
+	case errors.Is(err, repository.ErrInvalidDateRange):
+		http.Error(w, `{"error": "end_date must be >= start_date"}`, http.StatusBadRequest)
+	case errors.Is(err, repository.ErrUserNotFound):
+		// The body is well-formed but names a user that was never created.
+		http.Error(w, `{"error": "user not found"}`, http.StatusUnprocessableEntity)
+	case repository.IsConnectionError(err):
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, `{"error": "database unavailable"}`, http.StatusServiceUnavailable)
+	default:
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, msg), http.StatusInternalServerError)
+	}
+}
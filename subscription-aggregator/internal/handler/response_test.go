@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON_StalledClient(t *testing.T) {
+	orig := responseWriteTimeout
+	responseWriteTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { responseWriteTimeout = orig })
+
+	payload := strings.Repeat("x", 64<<20)
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		writeJSON(w, http.StatusOK, payload)
+	}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: test\r\n\r\n")
+	require.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler blocked writing to a client that stopped reading")
+	}
+}
+
+func TestPreferReturn(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefer []string
+		want   string
+	}{
+		{"absent", nil, returnRepresentation},
+		{"minimal", []string{"return=minimal"}, returnMinimal},
+		{"representation", []string{"return=representation"}, returnRepresentation},
+		{"case and spacing", []string{" Return = MINIMAL "}, returnMinimal},
+		{"among other preferences", []string{"respond-async, return=minimal"}, returnMinimal},
+		{"separate header values", []string{"wait=10", `return="minimal"`}, returnMinimal},
+		{"unrelated", []string{"handling=lenient"}, returnRepresentation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/subscriptions", nil)
+			for _, v := range tt.prefer {
+				r.Header.Add("Prefer", v)
+			}
+			require.Equal(t, tt.want, preferReturn(r))
+		})
+	}
+}
+
+func TestWriteMutation_Minimal(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/subscriptions", nil)
+	r.Header.Set("Prefer", "return=minimal")
+	rec := httptest.NewRecorder()
+
+	writeMutation(rec, r, http.StatusCreated, map[string]string{"id": "x"})
+
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Empty(t, rec.Body.String())
+	require.Equal(t, "return=minimal", rec.Header().Get("Preference-Applied"))
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+)
+
+func (h *SubscriptionHandler) GetAnnualSavings(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	annualPrice, err := strconv.Atoi(r.URL.Query().Get("annual_price"))
+	if err != nil || annualPrice <= 0 {
+		http.Error(w, `{"error": "annual_price must be a positive integer"}`, http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Annual savings lookup failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to calculate annual savings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, calculateAnnualSavings(sub.Price, annualPrice))
+}
+
+func calculateAnnualSavings(monthlyCost, annualPrice int) model.AnnualSavings {
+	yearlyAtMonthly := monthlyCost * 12
+	savings := yearlyAtMonthly - annualPrice
+
+	result := model.AnnualSavings{
+		AnnualSavings:   savings,
+		MonthlyCost:     monthlyCost,
+		AnnualPrice:     annualPrice,
+		RecommendAnnual: savings > 0,
+	}
+	if yearlyAtMonthly > 0 {
+		result.SavingsPct = math.Round(float64(savings)/float64(yearlyAtMonthly)*1000) / 10
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateAnnualSavings(t *testing.T) {
+	assert.Equal(t, model.AnnualSavings{
+		AnnualSavings:   1200,
+		MonthlyCost:     849,
+		AnnualPrice:     8988,
+		SavingsPct:      11.8,
+		RecommendAnnual: true,
+	}, calculateAnnualSavings(849, 8988))
+}
+
+func TestCalculateAnnualSavings_AnnualCostsMore(t *testing.T) {
+	result := calculateAnnualSavings(300, 3900)
+
+	assert.Equal(t, -300, result.AnnualSavings)
+	assert.Equal(t, -8.3, result.SavingsPct)
+	assert.False(t, result.RecommendAnnual)
+}
+
+func TestGetAnnualSavings_Validation(t *testing.T) {
+	h := NewSubscriptionHandler(repotest.NewFaultyRepo(nil).FailOn("GetByID", repository.ErrNotFound))
+	id := uuid.New().String()
+
+	for _, q := range []string{"", "?annual_price=abc", "?annual_price=0", "?annual_price=-10"} {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/"+id+"/annual-savings"+q, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, q)
+	}
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/"+id+"/annual-savings?annual_price=8999", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "boom"}`, http.StatusInternalServerError)
+	})
+
+	t.Run("plain HTTP", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		SecurityHeadersMiddleware(false)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+		assert.Equal(t, "1; mode=block", rec.Header().Get("X-XSS-Protection"))
+		assert.Equal(t, "default-src 'none'", rec.Header().Get("Content-Security-Policy"))
+		assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+	})
+
+	t.Run("HTTPS only", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		SecurityHeadersMiddleware(true)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subscriptions", nil))
+
+		assert.Equal(t, "max-age=63072000", rec.Header().Get("Strict-Transport-Security"))
+	})
+
+	t.Run("swagger UI keeps its own CSP", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		SecurityHeadersMiddleware(false)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+
+		assert.Empty(t, rec.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"subscription-aggregator/internal/model"
+)
+
+const (
+	fieldsParam  = "fields[subscriptions]"
+	includeParam = "include"
+)
+
+var subscriptionFields = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "metadata",
+	"sla_uptime_pct", "last_incident_at", "incident_count", "deleted_at", "created_at", "updated_at", "active_months"}
+
+// fieldset is a JSON:API sparse fieldset for subscriptions. A nil fieldset
+// keeps every field.
+type fieldset []string
+
+// parseFieldset reads fields[subscriptions] and rejects include, since
+// subscriptions have no related resources to embed.
+func parseFieldset(q url.Values) (fieldset, error) {
+	if include := q.Get(includeParam); include != "" {
+		return nil, fmt.Errorf("include is not supported: subscriptions have no related resources (got %q)", include)
+	}
+	if !q.Has(fieldsParam) {
+		return nil, nil
+	}
+
+	fields := fieldset{"id"}
+	for _, f := range strings.Split(q.Get(fieldsParam), ",") {
+		f = strings.TrimSpace(f)
+		if f == "" || slices.Contains(fields, f) {
+			continue
+		}
+		if !slices.Contains(subscriptionFields, f) {
+			return nil, fmt.Errorf("unknown field %q in %s", f, fieldsParam)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// shape returns sub restricted to the fieldset. The id is always kept so
+// clients can still address the resource.
+func (fs fieldset) shape(sub model.SubscriptionWithDerived) (any, error) {
+	if fs == nil {
+		return sub, nil
+	}
+	raw, err := json.Marshal(sub)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	shaped := make(map[string]json.RawMessage, len(fs))
+	for _, f := range fs {
+		if v, ok := all[f]; ok {
+			shaped[f] = v
+		}
+	}
+	return shaped, nil
+}
+
+func (fs fieldset) shapeAll(subs []model.SubscriptionWithDerived) ([]any, error) {
+	shaped := make([]any, 0, len(subs))
+	for _, sub := range subs {
+		s, err := fs.shape(sub)
+		if err != nil {
+			return nil, err
+		}
+		shaped = append(shaped, s)
+	}
+	return shaped, nil
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldset(t *testing.T) {
+	fs, err := parseFieldset(url.Values{})
+	require.NoError(t, err)
+	assert.Nil(t, fs, "no fields parameter keeps every field")
+
+	fs, err = parseFieldset(url.Values{fieldsParam: {"price, service_name,price"}})
+	require.NoError(t, err)
+	assert.Equal(t, fieldset{"id", "price", "service_name"}, fs)
+
+	_, err = parseFieldset(url.Values{fieldsParam: {"price,cost"}})
+	assert.EqualError(t, err, `unknown field "cost" in fields[subscriptions]`)
+}
+
+func TestSparseFieldset_ListSubscriptions(t *testing.T) {
+	end := monthdate.New(2025, time.December)
+	repo := listRepo{subs: []model.Subscription{
+		{ID: "1", ServiceName: "Spotify", Price: 300, UserID: uuid.New().String(), StartDate: monthdate.New(2025, time.January), EndDate: &end},
+	}}
+	h := NewSubscriptionHandler(countRepo{listRepo: repo})
+
+	q := url.Values{"user_id": {uuid.New().String()}, fieldsParam: {"price,service_name"}}
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions?"+q.Encode(), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var page struct {
+		Data []map[string]any `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	assert.Equal(t, []map[string]any{{"id": "1", "price": float64(300), "service_name": "Spotify"}}, page.Data)
+}
+
+func TestInclude_HistoryRejected(t *testing.T) {
+	h := NewSubscriptionHandler(listRepo{})
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/"+uuid.New().String()+"?include=history", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `include is not supported: subscriptions have no related resources (got \"history\")`)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const shareLinkTTL = 7 * 24 * time.Hour
+
+type shareConfig struct {
+	links   repository.ShareLinkRepository
+	secret  []byte
+	baseURL string
+}
+
+// WithShareLinks enables read-only share links signed with secret. Links are
+// rendered as baseURL + "/shared/{token}".
+func WithShareLinks(links repository.ShareLinkRepository, secret []byte, baseURL string) Option {
+	return func(h *SubscriptionHandler) {
+		h.share = &shareConfig{links: links, secret: secret, baseURL: strings.TrimRight(baseURL, "/")}
+	}
+}
+
+func (h *SubscriptionHandler) registerShareRoutes(mux *http.ServeMux) {
+	if h.share == nil {
+		return
+	}
+	mux.HandleFunc("POST /subscriptions/{id}/share", h.timeout(h.routeTimeout, h.knownParams(h.CreateShareLink)))
+	mux.HandleFunc("GET /shared/{token}", h.timeout(h.routeTimeout, h.knownParams(h.GetSharedSubscription)))
+}
+
+func (h *SubscriptionHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.repo.GetByID(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Share link lookup failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to create share link")
+		return
+	}
+
+	now := h.clock.Now()
+	expiresAt := now.Add(shareLinkTTL)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Subject:   id,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}).SignedString(h.share.secret)
+	if err != nil {
+		slog.Error("Failed to sign share token", "id", id, "error", err)
+		http.Error(w, `{"error": "failed to create share link"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.share.links.Create(r.Context(), hashShareToken(token), id, expiresAt); err != nil {
+		slog.Error("Failed to store share link", "id", id, "error", err)
+		writeRepoError(w, err, "failed to create share link")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, model.ShareLink{
+		ShareURL:  h.share.baseURL + "/shared/" + token,
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
+
+// GetSharedSubscription serves the subscription behind a share token. Invalid,
+// expired and unknown tokens all answer 404 so holders learn nothing from the
+// failure mode.
+func (h *SubscriptionHandler) GetSharedSubscription(w http.ResponseWriter, r *http.Request) {
+	token := r.PathValue("token")
+
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims,
+		func(*jwt.Token) (any, error) { return h.share.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(h.clock.Now),
+	)
+	if err != nil {
+		http.Error(w, `{"error": "share link not found or expired"}`, http.StatusNotFound)
+		return
+	}
+
+	id, err := h.share.links.SubscriptionID(r.Context(), hashShareToken(token), h.clock.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrShareLinkNotFound) {
+			http.Error(w, `{"error": "share link not found or expired"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Share link resolution failed", "error", err)
+		writeRepoError(w, err, "failed to load shared subscription")
+		return
+	}
+	if id != claims.Subject {
+		http.Error(w, `{"error": "share link not found or expired"}`, http.StatusNotFound)
+		return
+	}
+
+	sub, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, `{"error": "share link not found or expired"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Shared subscription lookup failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to load shared subscription")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.SharedSubscription{
+		ServiceName: sub.ServiceName,
+		Price:       sub.Price,
+		StartDate:   sub.StartDate,
+		EndDate:     sub.EndDate,
+	})
+}
+
+// hashShareToken is what gets stored, so a database leak does not yield
+// usable links.
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
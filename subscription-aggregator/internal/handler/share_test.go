@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memShareLinks struct {
+	links map[string]string
+	exp   map[string]time.Time
+}
+
+func (m *memShareLinks) Create(ctx context.Context, tokenHash, subscriptionID string, expiresAt time.Time) error {
+	m.links[tokenHash] = subscriptionID
+	m.exp[tokenHash] = expiresAt
+	return nil
+}
+
+func (m *memShareLinks) SubscriptionID(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+	id, ok := m.links[tokenHash]
+	if !ok || !now.Before(m.exp[tokenHash]) {
+		return "", repository.ErrShareLinkNotFound
+	}
+	return id, nil
+}
+
+type oneSubRepo struct {
+	repository.SubscriptionRepository
+	sub model.Subscription
+}
+
+func (o *oneSubRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
+	if id != o.sub.ID {
+		return nil, repository.ErrNotFound
+	}
+	sub := o.sub
+	return &sub, nil
+}
+
+func newShareHandler(t *testing.T) (*SubscriptionHandler, *apptime.FakeClock, *memShareLinks, string) {
+	t.Helper()
+	id := uuid.New().String()
+	repo := &oneSubRepo{sub: model.Subscription{
+		ID: id, ServiceName: "Netflix", Price: 800, UserID: uuid.New().String(), StartDate: monthdate.New(2025, time.July)}}
+	links := &memShareLinks{links: map[string]string{}, exp: map[string]time.Time{}}
+	clock := apptime.NewFakeClock(time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC))
+	h := NewSubscriptionHandler(repo, WithClock(clock), WithShareLinks(links, []byte("test-secret"), "https://app/"))
+	return h, clock, links, id
+}
+
+func createShare(t *testing.T, h *SubscriptionHandler, id string) string {
+	t.Helper()
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/"+id+"/share", nil))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var link model.ShareLink
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&link))
+	require.True(t, strings.HasPrefix(link.ShareURL, "https://app/shared/"), link.ShareURL)
+	return strings.TrimPrefix(link.ShareURL, "https://app/shared/")
+}
+
+func TestShareLink_RoundTrip(t *testing.T) {
+	h, _, links, id := newShareHandler(t)
+	token := createShare(t, h, id)
+
+	assert.NotContains(t, links.links, token, "only the token hash may be stored")
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/shared/"+token, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"service_name":"Netflix","price":800,"start_date":"07-2025"}`, rec.Body.String())
+}
+
+func TestShareLink_Expired(t *testing.T) {
+	h, clock, _, id := newShareHandler(t)
+	token := createShare(t, h, id)
+
+	clock.Advance(shareLinkTTL + time.Second)
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/shared/"+token, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestShareLink_Rejected(t *testing.T) {
+	h, _, links, id := newShareHandler(t)
+	token := createShare(t, h, id)
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/shared/"+token+"x", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "tampered signature")
+
+	for k := range links.links {
+		delete(links.links, k)
+	}
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/shared/"+token, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "validly signed but unknown to the database")
+
+	rec = serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/"+uuid.New().String()+"/share", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code, "unknown subscription")
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"subscription-aggregator/internal/export"
+	"subscription-aggregator/internal/repository"
+)
+
+// WithSheetsExporter enables POST /subscriptions/export/google-sheets.
+func WithSheetsExporter(exporter export.SheetsExporter) Option {
+	return func(h *SubscriptionHandler) {
+		h.sheets = exporter
+	}
+}
+
+type sheetsExportRequest struct {
+	SpreadsheetID string `json:"spreadsheet_id"`
+	SheetName     string `json:"sheet_name"`
+	UserID        string `json:"user_id"`
+}
+
+type sheetsExportResponse struct {
+	RowsWritten    int    `json:"rows_written"`
+	SpreadsheetURL string `json:"spreadsheet_url"`
+}
+
+func (h *SubscriptionHandler) ExportGoogleSheets(w http.ResponseWriter, r *http.Request) {
+	var req sheetsExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if req.SpreadsheetID == "" {
+		http.Error(w, `{"error": "spreadsheet_id is required"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validateUserID(req.UserID); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if req.SheetName == "" {
+		req.SheetName = "Subscriptions"
+	}
+
+	subs, err := h.repo.ListByUserID(r.Context(), req.UserID, repository.ListOptions{})
+	if err != nil {
+		slog.Error("Sheets export lookup failed", "user_id", req.UserID, "error", err)
+		writeRepoError(w, err, "failed to export subscriptions")
+		return
+	}
+
+	n, err := h.sheets.Export(r.Context(), req.SpreadsheetID, req.SheetName, subs)
+	if err != nil {
+		slog.Error("Sheets export failed", "spreadsheet_id", req.SpreadsheetID, "error", err)
+		http.Error(w, `{"error": "failed to write to google sheets"}`, http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sheetsExportResponse{
+		RowsWritten:    n,
+		SpreadsheetURL: export.SpreadsheetURL(req.SpreadsheetID),
+	})
+}
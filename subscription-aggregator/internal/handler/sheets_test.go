@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSheets struct {
+	spreadsheetID, sheetName string
+	subs                     []model.Subscription
+	err                      error
+}
+
+func (f *fakeSheets) Export(ctx context.Context, spreadsheetID, sheetName string, subs []model.Subscription) (int, error) {
+	f.spreadsheetID, f.sheetName, f.subs = spreadsheetID, sheetName, subs
+	return len(subs), f.err
+}
+
+func TestExportGoogleSheets(t *testing.T) {
+	userID := uuid.NewString()
+	subs := []model.Subscription{{ID: "a", ServiceName: "Netflix", UserID: userID}, {ID: "b", ServiceName: "Spotify", UserID: userID}}
+	sheets := &fakeSheets{}
+	h := NewSubscriptionHandler(listRepo{subs: subs}, WithSheetsExporter(sheets))
+
+	body := `{"spreadsheet_id":"sheet-1","user_id":"` + userID + `"}`
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/export/google-sheets", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp sheetsExportResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, 2, resp.RowsWritten)
+	assert.Equal(t, "https://docs.google.com/spreadsheets/d/sheet-1", resp.SpreadsheetURL)
+	assert.Equal(t, "Subscriptions", sheets.sheetName)
+	assert.Equal(t, subs, sheets.subs)
+}
+
+func TestExportGoogleSheets_Errors(t *testing.T) {
+	userID := uuid.NewString()
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{"missing spreadsheet", `{"user_id":"` + userID + `"}`, nil, http.StatusBadRequest},
+		{"bad user", `{"spreadsheet_id":"s","user_id":"nope"}`, nil, http.StatusBadRequest},
+		{"api failure", `{"spreadsheet_id":"s","user_id":"` + userID + `"}`, errors.New("status 403"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewSubscriptionHandler(listRepo{}, WithSheetsExporter(&fakeSheets{err: tt.err}))
+			rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/export/google-sheets", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestExportGoogleSheets_NotConfigured(t *testing.T) {
+	h := NewSubscriptionHandler(listRepo{})
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions/export/google-sheets", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
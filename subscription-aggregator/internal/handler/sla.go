@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"subscription-aggregator/internal/repository"
+)
+
+func (h *SubscriptionHandler) RecordSLAIncident(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	sub, err := h.repo.RecordIncident(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Record SLA incident failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to record SLA incident")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sub)
+}
+
+func (h *SubscriptionHandler) ListSLABreaches(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r)
+	var errs paramErrors
+	errors.As(err, &errs)
+	minIncidents := 1
+	if v := r.URL.Query().Get("min_incidents"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			errs = append(errs, "min_incidents must be a positive integer")
+		} else {
+			minIncidents = parsed
+		}
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.repo.ListSLABreaches(r.Context(), params.UserID, minIncidents)
+	if err != nil {
+		slog.Error("List SLA breaches failed", "user_id", params.UserID, "error", err)
+		writeRepoError(w, err, "failed to list SLA breaches")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginate(subs, params.Limit, params.Offset))
+}
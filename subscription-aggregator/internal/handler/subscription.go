@@ -1,63 +1,192 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
+	"subscription-aggregator/internal/export"
+	"subscription-aggregator/internal/ids"
+	"subscription-aggregator/internal/metrics"
 	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
 	"subscription-aggregator/internal/repository"
+	apptime "subscription-aggregator/internal/time"
 
 	"github.com/google/uuid"
 )
 
+const defaultAnomalyMultiplier = 1.5
+
 type SubscriptionHandler struct {
-	repo repository.SubscriptionRepository
+	repo              repository.SubscriptionRepository
+	anomalyMultiplier float64
+	strictQuery       bool
+	templates         repository.TemplateRepository
+	users             repository.UserRepository
+	clock             apptime.ClockSource
+	importMaxBytes    int64
+	adminToken        string
+	maxCostMonths     int
+	routeTimeout      time.Duration
+	slowRouteTimeout  time.Duration
+	share             *shareConfig
+	sheets            export.SheetsExporter
+	importJobs        *importJobStore
+	maxImportJobs     int
+	idScheme          ids.Scheme
+}
+
+type Option func(*SubscriptionHandler)
+
+func WithAnomalyMultiplier(multiplier float64) Option {
+	return func(h *SubscriptionHandler) {
+		h.anomalyMultiplier = multiplier
+	}
+}
+
+func WithStrictQueryParams(strict bool) Option {
+	return func(h *SubscriptionHandler) {
+		h.strictQuery = strict
+	}
+}
+
+func WithTemplates(templates repository.TemplateRepository) Option {
+	return func(h *SubscriptionHandler) {
+		h.templates = templates
+	}
+}
+
+func WithClock(clock apptime.ClockSource) Option {
+	return func(h *SubscriptionHandler) {
+		h.clock = clock
+	}
+}
+
+// WithIDScheme sets the format subscription ids are accepted in.
+func WithIDScheme(s ids.Scheme) Option {
+	return func(h *SubscriptionHandler) {
+		h.idScheme = s
+	}
+}
+
+func NewSubscriptionHandler(repo repository.SubscriptionRepository, opts ...Option) *SubscriptionHandler {
+	h := &SubscriptionHandler{
+		repo:              repo,
+		anomalyMultiplier: defaultAnomalyMultiplier,
+		clock:             apptime.RealClock{},
+		importMaxBytes:    defaultImportMaxBytes,
+		maxImportJobs:     DefaultMaxImportJobs,
+		maxCostMonths:     defaultMaxTotalCostMonths,
+		routeTimeout:      defaultRouteTimeout,
+		slowRouteTimeout:  defaultSlowRouteTimeout,
+		idScheme:          ids.DefaultScheme,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.importJobs = newImportJobStore(h.maxImportJobs)
+	return h
 }
 
-func NewSubscriptionHandler(repo repository.SubscriptionRepository) *SubscriptionHandler {
-	return &SubscriptionHandler{repo: repo}
+func (h *SubscriptionHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /subscriptions", h.timeout(h.routeTimeout, h.knownParams(h.CreateSubscription, "template_id")))
+	mux.HandleFunc("POST /subscriptions/import", h.timeout(h.slowRouteTimeout, h.knownParams(h.ImportSubscriptions, "upsert", "async")))
+	mux.HandleFunc("GET /subscriptions/import/jobs/{job_id}", h.timeout(h.routeTimeout, h.knownParams(h.GetImportJob)))
+	mux.HandleFunc("GET /subscriptions/{id}", h.timeout(h.routeTimeout, h.knownParams(h.GetSubscription, fieldsParam, includeParam)))
+	mux.HandleFunc("GET /subscriptions", h.timeout(h.routeTimeout, h.knownParams(h.ListSubscriptions,
+		"user_id", "service_name", "case_sensitive", "active_on", "include_deleted", "sort", "limit", "offset", "after", metadataParamPrefix, fieldsParam, includeParam)))
+	mux.HandleFunc("PUT /subscriptions/{id}", h.timeout(h.routeTimeout, h.knownParams(h.UpdateSubscription)))
+	mux.HandleFunc("PATCH /subscriptions/{id}", h.timeout(h.routeTimeout, h.knownParams(h.PatchSubscription)))
+	mux.HandleFunc("DELETE /subscriptions/{id}", h.timeout(h.routeTimeout, h.knownParams(h.DeleteSubscription)))
+	mux.HandleFunc("POST /subscriptions/{id}/restore", h.timeout(h.routeTimeout, h.knownParams(h.RestoreSubscription)))
+	mux.HandleFunc("GET /subscriptions/total-cost", h.timeout(h.slowRouteTimeout, h.knownParams(h.GetTotalCost,
+		"user_id", "service_name", "exclude_service", "case_sensitive", "itemize", "from", "to")))
+	mux.HandleFunc("GET /subscriptions/report", h.timeout(h.slowRouteTimeout, h.knownParams(h.GetCostReport, "user_id", "from", "to", "format")))
+	mux.HandleFunc("GET /subscriptions/cost-anomaly", h.timeout(h.routeTimeout, h.knownParams(h.GetCostAnomaly, "user_id")))
+	mux.HandleFunc("GET /subscriptions/stale", h.timeout(h.routeTimeout, h.knownParams(h.ListStaleSubscriptions, "user_id", "days", "limit", "offset")))
+	mux.HandleFunc("GET /subscriptions/projected-annual", h.timeout(h.routeTimeout, h.knownParams(h.GetProjectedAnnual, "user_id")))
+	mux.HandleFunc("GET /subscriptions/expiring-soon", h.timeout(h.routeTimeout, h.knownParams(h.ListExpiringSoon, "user_id", "months", "limit", "offset")))
+	mux.HandleFunc("GET /subscriptions/next-invoice", h.timeout(h.routeTimeout, h.knownParams(h.GetNextInvoice, "user_id")))
+	mux.HandleFunc("GET /subscriptions/sla-breaches", h.timeout(h.routeTimeout, h.knownParams(h.ListSLABreaches, "user_id", "min_incidents", "limit", "offset")))
+	mux.HandleFunc("GET /subscriptions/active-at", h.timeout(h.routeTimeout, h.knownParams(h.ListActiveAt, "user_id", "month", "limit", "offset")))
+	mux.HandleFunc("POST /subscriptions/{id}/duplicate-check", h.timeout(h.routeTimeout, h.knownParams(h.CheckDuplicates)))
+	mux.HandleFunc("POST /subscriptions/{id}/sla-incident", h.timeout(h.routeTimeout, h.knownParams(h.RecordSLAIncident)))
+	mux.HandleFunc("GET /subscriptions/{id}/annual-savings", h.timeout(h.routeTimeout, h.knownParams(h.GetAnnualSavings, "annual_price")))
+	mux.HandleFunc("GET /subscriptions/export", h.timeout(h.slowRouteTimeout, h.knownParams(h.ExportSubscriptions, "user_id", "format")))
+	mux.HandleFunc("GET /users/{user_id}/export", h.timeout(h.slowRouteTimeout, h.knownParams(h.ExportUserData)))
+	mux.HandleFunc("GET /users/{user_id}/dashboard", h.timeout(h.slowRouteTimeout, h.knownParams(h.GetUserDashboard, "from", "to")))
+	h.registerAdminRoutes(mux)
+	h.registerShareRoutes(mux)
+	h.registerUserRoutes(mux)
+	if h.sheets != nil {
+		mux.HandleFunc("POST /subscriptions/export/google-sheets", h.timeout(h.slowRouteTimeout, h.knownParams(h.ExportGoogleSheets)))
+	}
+	if h.templates != nil {
+		mux.HandleFunc("GET /templates", h.timeout(h.routeTimeout, h.knownParams(h.SearchTemplates, "search")))
+	}
 }
 
+// CreateSubscription godoc
+// @Summary      Create subscription
+// @Description  Create a new subscription record, optionally prefilled from a template
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        template_id   query     string              false  "Template to prefill fields from"
+// @Param        subscription  body      model.Subscription  true   "Subscription data"
+// @Success      201           {object}  model.Subscription
+// @Success      204           "Created, returned with Prefer: return=minimal"
+// @Failure      400           {object}  model.ErrorResponse  "Invalid body or field"
+// @Failure      409           {object}  model.ErrorResponse  "Subscription already exists, or the ID belongs to a deleted or another user's subscription"
+// @Failure      422           {object}  model.ErrorResponse  "end_date without start_date, or unknown user_id"
+// @Failure      500           {object}  model.ErrorResponse
+// @Router       /subscriptions [post]
 func (h *SubscriptionHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	var req model.Subscription
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
-		return
+	if templateID := r.URL.Query().Get("template_id"); templateID != "" {
+		if !h.applyTemplate(w, r, templateID, &req) {
+			return
+		}
 	}
 
-	if err := ValidateSubscriptionInput(req.ServiceName, req.Price, req.UserID, req.StartDate); err != nil {
+	// Fields present in the body override the template defaults.
+	if err := decodeSubscription(r, &req); err != nil {
 		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
 		return
 	}
 
-	if req.EndDate != nil {
-		if err := ValidatePeriodDate(*req.EndDate); err != nil {
-			http.Error(w, fmt.Sprintf(`{"error": "invalid end_date: %s"}`, err.Error()), http.StatusBadRequest)
-			return
-		}
-		if !isEndDateAfterOrEqual(req.StartDate, *req.EndDate) {
-			http.Error(w, `{"error": "end_date must be >= start_date"}`, http.StatusBadRequest)
-			return
-		}
+	if err := validateSubscription(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), validationStatus(err))
+		return
 	}
 
 	if err := h.repo.Create(r.Context(), &req); err != nil {
 		slog.Error("Create subscription failed", "error", err)
-		http.Error(w, `{"error": "failed to create subscription"}`, http.StatusInternalServerError)
+		writeRepoError(w, err, "failed to create subscription")
 		return
 	}
+	metrics.SubscriptionEvents.Inc(req.ServiceName, metrics.EventCreated)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(req); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
-	}
+	w.Header().Set("Location", "/subscriptions/"+req.ID)
+	writeMutation(w, r, http.StatusCreated, req)
 }
 
+// GetSubscription godoc
+// @Summary      Get subscription by ID
+// @Description  Get a single subscription by its UUID
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id                     path      string  true   "Subscription ID"
+// @Param        fields[subscriptions]  query     string  false  "Comma-separated fields to return; id is always included"
+// @Success      200                    {object}  model.SubscriptionWithDerived
+// @Failure      400                    {object}  model.ErrorResponse  "Invalid subscription ID or fieldset"
+// @Failure      404                    {object}  model.ErrorResponse  "Subscription not found"
+// @Failure      500                    {object}  model.ErrorResponse
+// @Router       /subscriptions/{id} [get]
 func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/subscriptions/")
 	if id == "" {
@@ -65,55 +194,154 @@ func (h *SubscriptionHandler) GetSubscription(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if _, err := uuid.Parse(id); err != nil {
+	if _, err := h.idScheme.Parse(id); err != nil {
 		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
 		return
 	}
+	fields, err := parseFieldset(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 
 	sub, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "subscription not found" {
+		if errors.Is(err, repository.ErrNotFound) {
 			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
 			return
 		}
 		slog.Error("Get subscription failed", "id", id, "error", err)
-		http.Error(w, `{"error": "internal error"}`, http.StatusInternalServerError)
+		writeRepoError(w, err, "internal error")
 		return
 	}
+	if err := h.repo.RecordAccess(r.Context(), id); err != nil {
+		slog.Warn("Record subscription access failed", "id", id, "error", err)
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(sub); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	shaped, err := fields.shape(model.NewSubscriptionWithDerived(*sub, monthdate.FromTime(h.clock.Now())))
+	if err != nil {
+		slog.Error("Shape subscription failed", "id", id, "error", err)
+		http.Error(w, `{"error": "internal error"}`, http.StatusInternalServerError)
 		return
 	}
+	writeJSON(w, http.StatusOK, shaped)
 }
 
+// ListSubscriptions godoc
+// @Summary      List subscriptions
+// @Description  Page through a user's subscriptions, most recently created first unless sort says otherwise. Any meta.<key> parameter filters on a top-level metadata key.
+// @Description  Passing after (empty for the first page) switches to cursor pagination, oldest start_date first with ties broken by id: limit is then 1-100 (default 20), filters, sort and offset are rejected, and the response is {data, next_cursor}.
+// @Tags         subscriptions
+// @Produce      json
+// @Param        user_id                query     string  true   "User ID (UUID)"
+// @Param        service_name           query     string  false  "Filter by service name"
+// @Param        case_sensitive         query     bool    false  "Match service_name case-sensitively"
+// @Param        active_on              query     string  false  "Only subscriptions running during this month (MM-YYYY)"
+// @Param        include_deleted        query     bool    false  "Also list deleted subscriptions; requires the admin token"
+// @Param        sort                   query     string  false  "Sort order"  Enums(created_at_asc, created_at_desc, price_asc, price_desc, start_date_asc, start_date_desc, service_name_asc)  default(created_at_desc)
+// @Param        limit                  query     int     false  "Page size (1-200)"  default(50)
+// @Param        offset                 query     int     false  "Rows to skip"       default(0)
+// @Param        after                  query     string  false  "Cursor from next_cursor of the previous page"
+// @Param        fields[subscriptions]  query     string  false  "Comma-separated fields to return; id is always included"
+// @Success      200                    {object}  model.PaginatedResponse[model.SubscriptionWithDerived]
+// @Failure      400                    {object}  model.ErrorResponse  "Invalid query parameters or fieldset"
+// @Failure      500                    {object}  model.ErrorResponse
+// @Router       /subscriptions [get]
 func (h *SubscriptionHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, `{"error": "user_id query parameter is required"}`, http.StatusBadRequest)
+	if r.URL.Query().Has("after") {
+		h.listSubscriptionsAfter(w, r)
+		return
+	}
+
+	params, err := parseListParams(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if params.IncludeDeleted && !h.authorizeAdmin(w, r) {
+		return
+	}
+	fields, err := parseFieldset(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
 		return
 	}
 
-	if _, err := uuid.Parse(userID); err != nil {
-		http.Error(w, `{"error": "user_id must be a valid UUID"}`, http.StatusBadRequest)
+	subs, err := h.repo.ListByUserID(r.Context(), params.UserID, params.ListOptions())
+	if err != nil {
+		slog.Error("List subscriptions failed", "user_id", params.UserID, "error", err)
+		writeRepoError(w, err, "failed to list subscriptions")
 		return
 	}
 
-	subs, err := h.repo.ListByUserID(r.Context(), userID)
+	total, err := h.repo.CountByUserID(r.Context(), params.UserID, params.ListOptions())
 	if err != nil {
-		slog.Error("List subscriptions failed", "user_id", userID, "error", err)
+		slog.Error("Count subscriptions failed", "user_id", params.UserID, "error", err)
+		writeRepoError(w, err, "failed to list subscriptions")
+		return
+	}
+
+	shaped, err := fields.shapeAll(h.withDerived(subs))
+	if err != nil {
+		slog.Error("Shape subscriptions failed", "user_id", params.UserID, "error", err)
 		http.Error(w, `{"error": "failed to list subscriptions"}`, http.StatusInternalServerError)
 		return
 	}
+	writeJSON(w, http.StatusOK, model.NewPaginatedResponse(shaped, total, params.Limit, params.Offset))
+}
+
+func (h *SubscriptionHandler) listSubscriptionsAfter(w http.ResponseWriter, r *http.Request) {
+	params, err := parseCursorParams(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	fields, err := parseFieldset(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(subs); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	subs, next, err := h.repo.Paginate(r.Context(), params.UserID, params.After, params.Limit)
+	if err != nil {
+		slog.Error("Paginate subscriptions failed", "user_id", params.UserID, "error", err)
+		writeRepoError(w, err, "failed to list subscriptions")
 		return
 	}
+
+	shaped, err := fields.shapeAll(h.withDerived(subs))
+	if err != nil {
+		slog.Error("Shape subscriptions failed", "user_id", params.UserID, "error", err)
+		http.Error(w, `{"error": "failed to list subscriptions"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, model.NewCursorPage(shaped, next))
+}
+
+func (h *SubscriptionHandler) withDerived(subs []model.Subscription) []model.SubscriptionWithDerived {
+	current := monthdate.FromTime(h.clock.Now())
+	derived := make([]model.SubscriptionWithDerived, len(subs))
+	for i, sub := range subs {
+		derived[i] = model.NewSubscriptionWithDerived(sub, current)
+	}
+	return derived
 }
 
+// UpdateSubscription godoc
+// @Summary      Create or replace subscription
+// @Description  Replace the subscription with the given ID, creating it if it does not exist. A deleted subscription must be restored first, and the user_id cannot change.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id            path      string              true  "Subscription ID"
+// @Param        subscription  body      model.Subscription  true  "Subscription data"
+// @Success      200           {object}  model.Subscription  "Replaced"
+// @Success      201           {object}  model.Subscription  "Created"
+// @Failure      400           {object}  model.ErrorResponse  "Invalid body or field"
+// @Failure      409           {object}  model.ErrorResponse  "Subscription already exists"
+// @Failure      422           {object}  model.ErrorResponse  "end_date without start_date, or unknown user_id"
+// @Failure      500           {object}  model.ErrorResponse
+// @Router       /subscriptions/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/subscriptions/")
 	if id == "" {
@@ -121,54 +349,159 @@ func (h *SubscriptionHandler) UpdateSubscription(w http.ResponseWriter, r *http.
 		return
 	}
 
-	var req model.Subscription
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+	if parsed, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	} else if parsed == uuid.Nil {
+		// PUT creates missing subscriptions, so this would store one under the nil UUID.
+		http.Error(w, `{"error": "subscription ID must not be the nil UUID"}`, http.StatusBadRequest)
 		return
 	}
 
-	if err := ValidateSubscriptionInput(req.ServiceName, req.Price, req.UserID, req.StartDate); err != nil {
+	var req model.Subscription
+	if err := decodeSubscription(r, &req); err != nil {
 		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
 		return
 	}
 
-	if req.EndDate != nil {
-		if err := ValidatePeriodDate(*req.EndDate); err != nil {
-			http.Error(w, fmt.Sprintf(`{"error": "invalid end_date: %s"}`, err.Error()), http.StatusBadRequest)
-			return
-		}
-		if !isEndDateAfterOrEqual(req.StartDate, *req.EndDate) {
-			http.Error(w, `{"error": "end_date must be >= start_date"}`, http.StatusBadRequest)
-			return
-		}
+	if err := validateSubscription(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), validationStatus(err))
+		return
 	}
 
 	req.ID = id
 
-	if err := h.repo.Update(r.Context(), id, &req); err != nil {
-		if err.Error() == "subscription not found" {
-			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
-			return
-		}
+	created, err := h.repo.Upsert(r.Context(), id, &req)
+	if err != nil {
 		slog.Error("Update subscription failed", "id", id, "error", err)
-		http.Error(w, `{"error": "failed to update subscription"}`, http.StatusInternalServerError)
+		writeRepoError(w, err, "failed to update subscription")
+		return
+	}
+	status := http.StatusOK
+	if created {
+		metrics.SubscriptionEvents.Inc(req.ServiceName, metrics.EventCreated)
+		status = http.StatusCreated
+	}
+
+	if preferReturn(r) == returnMinimal {
+		writeMutation(w, r, status, nil)
 		return
 	}
 
-	updated, err := h.repo.GetByID(r.Context(), id)
+	updated, err := h.repo.GetByID(repository.WithPrimary(r.Context()), id)
 	if err != nil {
 		slog.Warn("Updated subscription not found after update", "id", id)
-		http.Error(w, `{"error": "subscription updated but retrieval failed"}`, http.StatusInternalServerError)
+		writeRepoError(w, err, "subscription updated but retrieval failed")
+		return
+	}
+
+	writeJSON(w, status, updated)
+}
+
+// PatchSubscription godoc
+// @Summary      Update subscription fields
+// @Description  Change only the fields present in the body; omitted fields keep their values and metadata: null clears metadata
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id            path      string                     true  "Subscription ID"
+// @Param        subscription  body      model.PartialSubscription  true  "Fields to change"
+// @Success      200           {object}  model.Subscription
+// @Success      204           "Updated, returned with Prefer: return=minimal"
+// @Failure      400           {object}  model.ErrorResponse  "Invalid body or field"
+// @Failure      404           {object}  model.ErrorResponse  "Subscription not found"
+// @Failure      409           {object}  model.ErrorResponse  "Subscription already exists"
+// @Failure      422           {object}  model.ErrorResponse  "Unknown user_id"
+// @Failure      500           {object}  model.ErrorResponse
+// @Router       /subscriptions/{id} [patch]
+func (h *SubscriptionHandler) PatchSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req model.PartialSubscription
+	if err := decodeSubscription(r, &req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if err := validatePartialSubscription(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), validationStatus(err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(updated); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	fields := patchFields(req)
+	if len(fields) == 0 {
+		http.Error(w, `{"error": "request body must set at least one field"}`, http.StatusBadRequest)
 		return
 	}
+
+	if (req.StartDate == nil) != (req.EndDate == nil) {
+		current, err := h.repo.GetByID(repository.WithPrimary(r.Context()), id)
+		if err != nil {
+			slog.Error("Load subscription for patch failed", "id", id, "error", err)
+			writeRepoError(w, err, "failed to update subscription")
+			return
+		}
+		if err := validatePatchedDates(&req, current); err != nil {
+			http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+			return
+		}
+	}
+
+	sub, err := h.repo.Patch(r.Context(), id, fields)
+	if err != nil {
+		slog.Error("Patch subscription failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to update subscription")
+		return
+	}
+
+	writeMutation(w, r, http.StatusOK, sub)
+}
+
+// patchFields maps the fields present in a PATCH body to their columns.
+func patchFields(p model.PartialSubscription) map[string]any {
+	fields := make(map[string]any)
+	if p.ServiceName != nil {
+		fields["service_name"] = *p.ServiceName
+	}
+	if p.Price != nil {
+		fields["price"] = *p.Price
+	}
+	if p.UserID != nil {
+		fields["user_id"] = *p.UserID
+	}
+	if p.StartDate != nil {
+		fields["start_date"] = *p.StartDate
+	}
+	if p.EndDate != nil {
+		fields["end_date"] = *p.EndDate
+	}
+	if p.Metadata != nil {
+		if string(p.Metadata) == "null" {
+			fields["metadata"] = nil
+		} else {
+			fields["metadata"] = p.Metadata
+		}
+	}
+	if p.SLAUptimePct != nil {
+		fields["sla_uptime_pct"] = *p.SLAUptimePct
+	}
+	return fields
 }
 
+// DeleteSubscription godoc
+// @Summary      Delete subscription
+// @Description  Delete a subscription by ID. It is kept as deleted and can be brought back with POST /subscriptions/{id}/restore
+// @Tags         subscriptions
+// @Param        id   path  string  true  "Subscription ID"
+// @Success      204
+// @Failure      400  {object}  model.ErrorResponse  "Invalid subscription ID"
+// @Failure      404  {object}  model.ErrorResponse  "Subscription not found"
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /subscriptions/{id} [delete]
 func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/subscriptions/")
 	if id == "" {
@@ -176,54 +509,352 @@ func (h *SubscriptionHandler) DeleteSubscription(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if _, err := uuid.Parse(id); err != nil {
+	if _, err := h.idScheme.Parse(id); err != nil {
 		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
 		return
 	}
 
+	sub, err := h.repo.GetByID(repository.WithPrimary(r.Context()), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Delete subscription failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to delete subscription")
+		return
+	}
+
 	if err := h.repo.Delete(r.Context(), id); err != nil {
-		if err.Error() == "subscription not found" {
+		if errors.Is(err, repository.ErrNotFound) {
 			http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
 			return
 		}
 		slog.Error("Delete subscription failed", "id", id, "error", err)
-		http.Error(w, `{"error": "failed to delete subscription"}`, http.StatusInternalServerError)
+		writeRepoError(w, err, "failed to delete subscription")
 		return
 	}
+	metrics.SubscriptionEvents.Inc(sub.ServiceName, metrics.EventCancelled)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *SubscriptionHandler) GetTotalCost(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	serviceName := r.URL.Query().Get("service_name")
-	from := r.URL.Query().Get("from")
-	to := r.URL.Query().Get("to")
+// RestoreSubscription godoc
+// @Summary      Restore subscription
+// @Description  Undo the deletion of a subscription and return it
+// @Tags         subscriptions
+// @Produce      json
+// @Param        id   path      string              true  "Subscription ID"
+// @Success      200  {object}  model.Subscription
+// @Failure      400  {object}  model.ErrorResponse  "Invalid subscription ID"
+// @Failure      404  {object}  model.ErrorResponse  "No deleted subscription with this ID"
+// @Failure      409  {object}  model.ErrorResponse  "A live subscription with the same service and start date exists"
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /subscriptions/{id}/restore [post]
+func (h *SubscriptionHandler) RestoreSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Restore(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.Error(w, `{"error": "deleted subscription not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Restore subscription failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to restore subscription")
+		return
+	}
+
+	sub, err := h.repo.GetByID(repository.WithPrimary(r.Context()), id)
+	if err != nil {
+		slog.Error("Load restored subscription failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to restore subscription")
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
 
+const defaultMaxTotalCostMonths = 120
+
+func WithMaxTotalCostMonths(months int) Option {
+	return func(h *SubscriptionHandler) {
+		h.maxCostMonths = months
+	}
+}
+
+// validatePeriod checks the required from..to months of the per-user cost
+// endpoints.
+func (h *SubscriptionHandler) validatePeriod(from, to string) error {
 	if from == "" || to == "" {
+		return fmt.Errorf("'from' and 'to' query parameters are required")
+	}
+	if err := ValidatePeriodDate(from); err != nil {
+		return fmt.Errorf("invalid from: %w", err)
+	}
+	if err := ValidatePeriodDate(to); err != nil {
+		return fmt.Errorf("invalid to: %w", err)
+	}
+	return h.validateCostWindow(from, to)
+}
+
+// validateCostWindow bounds the inclusive from..to span; both must already be
+// valid MM-YYYY strings.
+func (h *SubscriptionHandler) validateCostWindow(fromStr, toStr string) error {
+	from, err := monthdate.Parse(fromStr)
+	if err != nil {
+		return fmt.Errorf("invalid from: %w", err)
+	}
+	to, err := monthdate.Parse(toStr)
+	if err != nil {
+		return fmt.Errorf("invalid to: %w", err)
+	}
+	if to.Before(from) {
+		return fmt.Errorf("'from' must not be after 'to'")
+	}
+	if months := monthdate.MonthsBetween(from, to); months > h.maxCostMonths {
+		return fmt.Errorf("period spans %d months, the maximum is %d", months, h.maxCostMonths)
+	}
+	return nil
+}
+
+// GetTotalCost godoc
+// @Summary      Get total subscription cost
+// @Description  Sum the prices of a user's subscriptions active in the from..to period
+// @Tags         subscriptions
+// @Produce      json
+// @Param        user_id          query     string  true   "User ID (UUID)"
+// @Param        from             query     string  true   "Start of period (MM-YYYY)"  example(01-2025)
+// @Param        to               query     string  true   "End of period (MM-YYYY)"    example(12-2025)
+// @Param        service_name     query     string  false  "Only this service"
+// @Param        exclude_service  query     string  false  "Every service except this one"
+// @Param        case_sensitive   query     bool    false  "Match service names case-sensitively"
+// @Param        itemize          query     bool    false  "Include each contributing subscription"
+// @Success      200              {object}  model.CostSummary
+// @Failure      400              {object}  model.ErrorResponse  "Invalid period or filters"
+// @Failure      500              {object}  model.ErrorResponse
+// @Router       /subscriptions/total-cost [get]
+func (h *SubscriptionHandler) GetTotalCost(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := repository.CostFilter{
+		UserID:         q.Get("user_id"),
+		ServiceName:    q.Get("service_name"),
+		ExcludeService: q.Get("exclude_service"),
+		From:           q.Get("from"),
+		To:             q.Get("to"),
+	}
+
+	if filter.From == "" || filter.To == "" {
 		http.Error(w, `{"error": "'from' and 'to' query parameters are required"}`, http.StatusBadRequest)
 		return
 	}
-	if userID == "" {
+	if filter.UserID == "" {
 		http.Error(w, `{"error": "'user_id' is required"}`, http.StatusBadRequest)
 		return
 	}
+	if err := ValidatePeriodDate(filter.From); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": "invalid from: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := ValidatePeriodDate(filter.To); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": "invalid to: %s"}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if err := h.validateCostWindow(filter.From, filter.To); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	if filter.ServiceName != "" && filter.ExcludeService != "" {
+		http.Error(w, `{"error": "service_name and exclude_service cannot be combined"}`, http.StatusBadRequest)
+		return
+	}
+	if q.Has("exclude_service") && validateServiceName(filter.ExcludeService) != nil {
+		http.Error(w, `{"error": "exclude_service must be a valid service name"}`, http.StatusBadRequest)
+		return
+	}
+	caseSensitive, err := parseBool(q, "case_sensitive", false)
+	if err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+	filter.CaseSensitive = caseSensitive
+	if filter.Itemize, err = parseBool(q, "itemize", false); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
 
-	total, err := h.repo.TotalCost(r.Context(), userID, serviceName, from, to)
+	summary, err := h.repo.TotalCost(r.Context(), filter)
 	if err != nil {
 		if strings.Contains(err.Error(), "invalid") {
 			http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
 			return
 		}
-		slog.Error("Total cost calculation failed", "user_id", userID, "error", err)
-		http.Error(w, `{"error": "failed to calculate total cost"}`, http.StatusInternalServerError)
+		slog.Error("Total cost calculation failed", "user_id", filter.UserID, "error", err)
+		writeRepoError(w, err, "failed to calculate total cost")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// CheckDuplicates godoc
+// @Summary      Find likely duplicates
+// @Description  List other subscriptions of the same user that look like duplicates of the given data
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        id            path      string              true  "Subscription ID to exclude"
+// @Param        subscription  body      model.Subscription  true  "Subscription data to compare"
+// @Success      200           {object}  map[string][]model.Duplicate
+// @Failure      400           {object}  model.ErrorResponse  "Invalid body or field"
+// @Failure      422           {object}  model.ErrorResponse  "end_date without start_date"
+// @Failure      500           {object}  model.ErrorResponse
+// @Router       /subscriptions/{id}/duplicate-check [post]
+func (h *SubscriptionHandler) CheckDuplicates(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.idScheme.Parse(id); err != nil {
+		http.Error(w, `{"error": "invalid subscription ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	var req model.Subscription
+	if err := decodeSubscription(r, &req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateSubscription(&req); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, err.Error()), validationStatus(err))
+		return
+	}
+
+	duplicates, err := h.repo.FindDuplicates(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("Duplicate check failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to check duplicates")
+		return
+	}
+
+	response := map[string][]model.Duplicate{"duplicates": duplicates}
+	writeJSON(w, http.StatusOK, response)
+}
+
+const (
+	defaultStaleDays = 90
+	maxStaleDays     = 3650
+)
+
+// ListStaleSubscriptions godoc
+// @Summary      List stale subscriptions
+// @Description  List subscriptions not read through the API for the given number of days
+// @Tags         subscriptions
+// @Produce      json
+// @Param        user_id  query     string  true   "User ID (UUID)"
+// @Param        days     query     int     false  "Days without access"  default(90)
+// @Param        limit    query     int     false  "Page size (1-200)"    default(50)
+// @Param        offset   query     int     false  "Rows to skip"         default(0)
+// @Success      200      {object}  model.PaginatedResponse[model.Subscription]
+// @Failure      400      {object}  model.ErrorResponse  "Invalid query parameters"
+// @Failure      500      {object}  model.ErrorResponse
+// @Router       /subscriptions/stale [get]
+func (h *SubscriptionHandler) ListStaleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r)
+	var errs paramErrors
+	errors.As(err, &errs)
+	days, err := parseIntParam(r.URL.Query(), "days", defaultStaleDays, 1, maxStaleDays)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	subs, err := h.repo.ListStale(r.Context(), params.UserID, days)
+	if err != nil {
+		slog.Error("List stale subscriptions failed", "user_id", params.UserID, "error", err)
+		writeRepoError(w, err, "failed to list stale subscriptions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginate(subs, params.Limit, params.Offset))
+}
+
+const (
+	defaultExpiringMonths = 3
+	maxExpiringMonths     = 24
+)
+
+// ListActiveAt godoc
+// @Summary      List subscriptions active in a month
+// @Tags         subscriptions
+// @Produce      json
+// @Param        user_id  query     string  true   "User ID (UUID)"
+// @Param        month    query     string  true   "Month (MM-YYYY)"    example(05-2024)
+// @Param        limit    query     int     false  "Page size (1-200)"  default(50)
+// @Param        offset   query     int     false  "Rows to skip"       default(0)
+// @Success      200      {object}  model.PaginatedResponse[model.Subscription]
+// @Failure      400      {object}  model.ErrorResponse  "Invalid query parameters"
+// @Failure      500      {object}  model.ErrorResponse
+// @Router       /subscriptions/active-at [get]
+func (h *SubscriptionHandler) ListActiveAt(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r)
+	var errs paramErrors
+	errors.As(err, &errs)
+	month := r.URL.Query().Get("month")
+	if err := ValidatePeriodDate(month); err != nil {
+		errs = append(errs, "invalid month: "+err.Error())
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
 		return
 	}
 
-	response := map[string]int{"total": total}
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	subs, err := h.repo.ListActiveAt(r.Context(), params.UserID, month)
+	if err != nil {
+		slog.Error("List active subscriptions failed", "user_id", params.UserID, "month", month, "error", err)
+		writeRepoError(w, err, "failed to list active subscriptions")
 		return
 	}
+
+	writeJSON(w, http.StatusOK, paginate(subs, params.Limit, params.Offset))
+}
+
+// ListExpiringSoon godoc
+// @Summary      List subscriptions expiring soon
+// @Description  List subscriptions whose end_date falls within the next months
+// @Tags         subscriptions
+// @Produce      json
+// @Param        user_id  query     string  true   "User ID (UUID)"
+// @Param        months   query     int     false  "Look-ahead in months (1-24)"  default(3)
+// @Param        limit    query     int     false  "Page size (1-200)"            default(50)
+// @Param        offset   query     int     false  "Rows to skip"                 default(0)
+// @Success      200      {object}  model.PaginatedResponse[model.Subscription]
+// @Failure      400      {object}  model.ErrorResponse  "Invalid query parameters"
+// @Failure      500      {object}  model.ErrorResponse
+// @Router       /subscriptions/expiring-soon [get]
+func (h *SubscriptionHandler) ListExpiringSoon(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r)
+	var errs paramErrors
+	errors.As(err, &errs)
+	months, err := parseIntParam(r.URL.Query(), "months", defaultExpiringMonths, 1, maxExpiringMonths)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		http.Error(w, fmt.Sprintf(`{"error": %q}`, errs.Error()), http.StatusBadRequest)
+		return
+	}
+
+	from := monthdate.FromTime(h.clock.Now())
+	subs, err := h.repo.ListExpiring(r.Context(), params.UserID, from, from.AddMonths(months))
+	if err != nil {
+		slog.Error("List expiring subscriptions failed", "user_id", params.UserID, "error", err)
+		writeRepoError(w, err, "failed to list expiring subscriptions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paginate(subs, params.Limit, params.Offset))
 }
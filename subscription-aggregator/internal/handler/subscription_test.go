@@ -0,0 +1,429 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/ids"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/internal/repository/repotest"
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(h *SubscriptionHandler, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// listRepo serves a fixed set of subscriptions to list endpoints.
+type listRepo struct {
+	repository.SubscriptionRepository
+	subs []model.Subscription
+}
+
+func (l listRepo) ListByUserID(ctx context.Context, userID string, opts repository.ListOptions) ([]model.Subscription, error) {
+	return l.subs, nil
+}
+
+func (l listRepo) ListActiveAt(ctx context.Context, userID, month string) ([]model.Subscription, error) {
+	return l.subs, nil
+}
+
+func (l listRepo) ListExpiring(ctx context.Context, userID string, from, to monthdate.MonthDate) ([]model.Subscription, error) {
+	return l.subs, nil
+}
+
+func (l listRepo) ListSLABreaches(ctx context.Context, userID string, minIncidents int) ([]model.Subscription, error) {
+	return l.subs, nil
+}
+
+func (l listRepo) ListStale(ctx context.Context, userID string, days int) ([]model.Subscription, error) {
+	return l.subs, nil
+}
+
+// Paginate expects subs in (start_date, id) order, as the database returns them.
+func (l listRepo) Paginate(ctx context.Context, userID string, after *repository.Cursor, limit int) ([]model.Subscription, string, error) {
+	start := 0
+	if after != nil {
+		start = slices.IndexFunc(l.subs, func(s model.Subscription) bool {
+			return s.StartDate.After(after.StartDate) || (s.StartDate == after.StartDate && s.ID > after.ID)
+		})
+		if start < 0 {
+			return nil, "", nil
+		}
+	}
+	page := l.subs[start:min(start+limit, len(l.subs))]
+	if start+limit >= len(l.subs) {
+		return page, "", nil
+	}
+	last := page[len(page)-1]
+	return page, repository.EncodeCursor(repository.Cursor{StartDate: last.StartDate, ID: last.ID}), nil
+}
+
+type countRepo struct {
+	listRepo
+}
+
+func (c countRepo) CountByUserID(ctx context.Context, userID string, opts repository.ListOptions) (int, error) {
+	return len(c.subs), nil
+}
+
+func TestGetSubscription_NotFound(t *testing.T) {
+	repo := repotest.NewFaultyRepo(nil).FailOn("GetByID", repository.ErrNotFound)
+	h := NewSubscriptionHandler(repo)
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/"+uuid.New().String(), nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), "subscription not found")
+}
+
+func TestGetSubscription_IDScheme(t *testing.T) {
+	id := ids.ULID.Format(ids.ULID.New())
+	repo := &patchRepo{sub: model.Subscription{ID: id, ServiceName: "Netflix", Price: 500, UserID: uuid.NewString(),
+		StartDate: monthdate.New(2025, time.March)}}
+
+	rec := serve(NewSubscriptionHandler(repo), httptest.NewRequest(http.MethodGet, "/subscriptions/"+id, nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "ULIDs are rejected by default")
+
+	h := NewSubscriptionHandler(repo, WithIDScheme(ids.ULID))
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/"+id, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":"`+id+`"`)
+	assert.Equal(t, 1, repo.accessed, "a client GET records access")
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/not-an-id", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListSubscriptions_ConnectionError(t *testing.T) {
+	connErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	repo := repotest.NewFaultyRepo(nil).FailOn("ListByUserID", fmt.Errorf("database query failed: %w", connErr))
+	h := NewSubscriptionHandler(repo)
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions?user_id="+uuid.New().String(), nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+}
+
+func TestDeleteSubscription_InternalError(t *testing.T) {
+	repo := repotest.NewFaultyRepo(nil).FailOn("GetByID", errors.New("database query failed: syntax error"))
+	h := NewSubscriptionHandler(repo)
+
+	rec := serve(h, httptest.NewRequest(http.MethodDelete, "/subscriptions/"+uuid.New().String(), nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "failed to delete subscription")
+}
+
+func TestCreateSubscription_InvalidDateRangeFromDatabase(t *testing.T) {
+	repo := repotest.NewFaultyRepo(nil).FailOn("Create", repository.ErrInvalidDateRange)
+	h := NewSubscriptionHandler(repo)
+
+	body := `{"service_name": "Spotify", "price": 300, "user_id": "` + uuid.NewString() + `", "start_date": "01-2025"}`
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":"end_date must be >= start_date"}`, rec.Body.String())
+}
+
+func TestExportUserData_InvalidUserID(t *testing.T) {
+	h := NewSubscriptionHandler(repotest.NewFaultyRepo(nil))
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/users/not-a-uuid/export", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetTotalCost_WindowLimit(t *testing.T) {
+	repo := repotest.NewFaultyRepo(nil).FailOn("TotalCost", errors.New("database aggregation failed: boom"))
+	h := NewSubscriptionHandler(repo, WithMaxTotalCostMonths(120))
+	userID := uuid.New().String()
+
+	totalCost := func(from, to string) *httptest.ResponseRecorder {
+		return serve(h, httptest.NewRequest(http.MethodGet,
+			"/subscriptions/total-cost?user_id="+userID+"&from="+from+"&to="+to, nil))
+	}
+
+	rec := totalCost("01-2020", "12-2029")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "120 months is allowed and reaches the repository")
+
+	rec = totalCost("01-2020", "01-2030")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "period spans 121 months, the maximum is 120")
+
+	rec = totalCost("02-2025", "01-2025")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "'from' must not be after 'to'")
+}
+
+func TestListSubscriptions_Cursor(t *testing.T) {
+	var subs []model.Subscription
+	for i := range 5 {
+		subs = append(subs, model.Subscription{
+			ID:          fmt.Sprintf("00000000-0000-4000-8000-00000000000%d", i),
+			ServiceName: "Paged",
+			StartDate:   monthdate.New(2024+i/2, time.December),
+		})
+	}
+	h := NewSubscriptionHandler(listRepo{subs: subs})
+	base := "/subscriptions?user_id=" + uuid.New().String()
+
+	var page model.CursorPage[model.Subscription]
+	var ids []string
+	after := ""
+	for range 3 {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, base+"&limit=2&after="+after, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		page = model.CursorPage[model.Subscription]{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		for _, s := range page.Data {
+			ids = append(ids, s.ID)
+		}
+		after = page.NextCursor
+	}
+	assert.Len(t, ids, 5)
+	assert.Equal(t, subs[4].ID, ids[4])
+	assert.Empty(t, page.NextCursor, "last page has no cursor")
+
+	last := repository.EncodeCursor(repository.Cursor{StartDate: subs[4].StartDate, ID: subs[4].ID})
+	rec := serve(h, httptest.NewRequest(http.MethodGet, base+"&after="+last, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[],"next_cursor":""}`, rec.Body.String())
+
+	for _, q := range []string{"&after=nope", "&after=e30", "&after=&limit=101", "&after=&offset=5", "&after=&meta.plan=pro"} {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, base+q, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, q)
+	}
+}
+
+func TestListStaleSubscriptions(t *testing.T) {
+	subs := make([]model.Subscription, 3)
+	for i := range subs {
+		subs[i] = model.Subscription{ID: uuid.NewString(), ServiceName: "Spotify", StartDate: monthdate.New(2024, time.January)}
+	}
+	h := NewSubscriptionHandler(listRepo{subs: subs})
+	base := "/subscriptions/stale?user_id=" + uuid.NewString()
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, base+"&limit=2&offset=1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var page model.PaginatedResponse[model.Subscription]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, []model.Subscription{subs[1], subs[2]}, page.Data)
+	assert.Equal(t, model.Pagination{Total: 3, Limit: 2, Offset: 1, HasPrev: true}, page.Pagination)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/stale?user_id="+uuid.Nil.String()+"&days=0", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error": "user_id must not be the nil UUID; days must be an integer between 1 and 3650"}`, rec.Body.String())
+}
+
+func TestListExpiringSoon(t *testing.T) {
+	end := monthdate.New(2025, time.August)
+	subs := []model.Subscription{{ID: uuid.NewString(), ServiceName: "Netflix", StartDate: monthdate.New(2024, time.February), EndDate: &end}}
+	h := NewSubscriptionHandler(listRepo{subs: subs})
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/expiring-soon?user_id="+uuid.NewString(), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var page model.PaginatedResponse[model.Subscription]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, subs, page.Data)
+	assert.Equal(t, model.Pagination{Total: 1, Limit: defaultListLimit}, page.Pagination)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/expiring-soon?user_id="+uuid.Nil.String()+"&months=25", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error": "user_id must not be the nil UUID; months must be an integer between 1 and 24"}`, rec.Body.String())
+}
+
+func TestListSLABreaches(t *testing.T) {
+	subs := []model.Subscription{{ID: uuid.NewString(), ServiceName: "Netflix", StartDate: monthdate.New(2024, time.February)}}
+	h := NewSubscriptionHandler(listRepo{subs: subs})
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/sla-breaches?user_id="+uuid.NewString(), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var page model.PaginatedResponse[model.Subscription]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, subs, page.Data)
+	assert.Equal(t, 1, page.Pagination.Total)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/sla-breaches?user_id="+uuid.Nil.String()+"&min_incidents=0", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error": "user_id must not be the nil UUID; min_incidents must be a positive integer"}`, rec.Body.String())
+}
+
+func TestListActiveAt(t *testing.T) {
+	subs := []model.Subscription{
+		{ID: uuid.NewString(), ServiceName: "Netflix", StartDate: monthdate.New(2024, time.February)},
+		{ID: uuid.NewString(), ServiceName: "Spotify", StartDate: monthdate.New(2024, time.January)},
+	}
+	h := NewSubscriptionHandler(listRepo{subs: subs})
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/active-at?month=05-2024&limit=1&user_id="+uuid.NewString(), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var page model.PaginatedResponse[model.Subscription]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, subs[:1], page.Data)
+	assert.Equal(t, model.Pagination{Total: 2, Limit: 1, HasNext: true}, page.Pagination)
+
+	rec = serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/active-at?user_id="+uuid.Nil.String(), nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error": "user_id must not be the nil UUID; invalid month: date must be in MM-YYYY format"}`, rec.Body.String())
+}
+
+func TestListActiveAt_InvalidMonth(t *testing.T) {
+	h := NewSubscriptionHandler(repotest.NewFaultyRepo(nil))
+	userID := uuid.New().String()
+
+	for _, month := range []string{"", "5-2024", "13-2024", "2024-05"} {
+		rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions/active-at?user_id="+userID+"&month="+month, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, month)
+		assert.Contains(t, rec.Body.String(), "invalid month: date must be in MM-YYYY format", month)
+	}
+}
+
+func TestUpdateSubscription_EndDateWithoutStartDate(t *testing.T) {
+	h := NewSubscriptionHandler(repotest.NewFaultyRepo(nil))
+
+	req := httptest.NewRequest(http.MethodPut, "/subscriptions/"+uuid.New().String(),
+		strings.NewReader(`{"end_date": "12-2025"}`))
+	rec := serve(h, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":"end_date requires start_date to be set"}`, rec.Body.String())
+}
+
+// patchRepo applies Patch to a single stored subscription.
+type patchRepo struct {
+	repository.SubscriptionRepository
+	sub      model.Subscription
+	fields   map[string]any
+	accessed int
+}
+
+func (p *patchRepo) RecordAccess(ctx context.Context, id string) error {
+	p.accessed++
+	return nil
+}
+
+func (p *patchRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
+	if id != p.sub.ID {
+		return nil, repository.ErrNotFound
+	}
+	sub := p.sub
+	return &sub, nil
+}
+
+func (p *patchRepo) Patch(ctx context.Context, id string, fields map[string]any) (*model.Subscription, error) {
+	if id != p.sub.ID {
+		return nil, repository.ErrNotFound
+	}
+	p.fields = fields
+	if v, ok := fields["price"]; ok {
+		p.sub.Price = v.(int)
+	}
+	if v, ok := fields["end_date"]; ok {
+		end := v.(monthdate.MonthDate)
+		p.sub.EndDate = &end
+	}
+	sub := p.sub
+	return &sub, nil
+}
+
+func TestPatchSubscription(t *testing.T) {
+	repo := &patchRepo{sub: model.Subscription{
+		ID: uuid.NewString(), ServiceName: "Spotify", Price: 300,
+		UserID: uuid.NewString(), StartDate: monthdate.New(2025, time.January),
+	}}
+	h := NewSubscriptionHandler(repo)
+	patch := func(id, body string) *httptest.ResponseRecorder {
+		return serve(h, httptest.NewRequest(http.MethodPatch, "/subscriptions/"+id, strings.NewReader(body)))
+	}
+
+	rec := patch(repo.sub.ID, `{"price": 349, "end_date": "12-2025"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]any{"price": 349, "end_date": monthdate.New(2025, time.December)}, repo.fields,
+		"only the fields in the body are written")
+	var got model.Subscription
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, 349, got.Price)
+	assert.Equal(t, "Spotify", got.ServiceName)
+	assert.Equal(t, "12-2025", got.EndDate.String())
+
+	rec = patch(repo.sub.ID, `{"metadata": null}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]any{"metadata": nil}, repo.fields)
+
+	for _, body := range []string{
+		`{}`,
+		`{"price": 0}`,
+		`{"service_name": ""}`,
+		`{"user_id": "nope"}`,
+		`{"start_date": "2025-01"}`,
+		`{"start_date": "06-2025", "end_date": "01-2025"}`,
+		`{"sla_uptime_pct": 101}`,
+		`{"metadata": [1]}`,
+		`not json`,
+	} {
+		rec := patch(repo.sub.ID, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+
+	// The stored subscription runs 01-2025..12-2025 by now.
+	for _, body := range []string{`{"end_date": "12-2024"}`, `{"start_date": "01-2026"}`} {
+		rec := patch(repo.sub.ID, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		assert.Contains(t, rec.Body.String(), "end_date must be >= start_date", body)
+	}
+	rec = patch(repo.sub.ID, `{"start_date": "06-2025"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = patch(uuid.NewString(), `{"end_date": "12-2025"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = patch(uuid.NewString(), `{"price": 100}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = patch("not-a-uuid", `{"price": 100}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Zero(t, repo.accessed, "internal read-backs do not count as access")
+}
+
+func TestListSubscriptions_ActiveMonths(t *testing.T) {
+	end := monthdate.New(2025, time.June)
+	h := NewSubscriptionHandler(countRepo{listRepo{subs: []model.Subscription{
+		{ID: uuid.NewString(), ServiceName: "Open", StartDate: monthdate.New(2025, time.January)},
+		{ID: uuid.NewString(), ServiceName: "Ended", StartDate: monthdate.New(2025, time.January), EndDate: &end},
+		{ID: uuid.NewString(), ServiceName: "Future", StartDate: monthdate.New(2026, time.January)},
+	}}}, WithClock(apptime.NewFakeClock(time.Date(2025, time.October, 15, 0, 0, 0, 0, time.UTC))))
+
+	rec := serve(h, httptest.NewRequest(http.MethodGet, "/subscriptions?user_id="+uuid.NewString()+"&fields%5Bsubscriptions%5D=active_months", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var page struct {
+		Data []struct {
+			ActiveMonths int `json:"active_months"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	var months []int
+	for _, s := range page.Data {
+		months = append(months, s.ActiveMonths)
+	}
+	assert.Equal(t, []int{10, 6, 0}, months)
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+)
+
+const templateSearchLimit = 10
+
+func (h *SubscriptionHandler) SearchTemplates(w http.ResponseWriter, r *http.Request) {
+	search := strings.TrimSpace(r.URL.Query().Get("search"))
+
+	templates, err := h.templates.Search(r.Context(), search, templateSearchLimit)
+	if err != nil {
+		slog.Error("Search templates failed", "search", search, "error", err)
+		writeRepoError(w, err, "failed to search templates")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, templates)
+}
+
+// applyTemplate pre-fills sub from the template identified by templateID.
+// It writes the error response itself and reports whether to continue.
+func (h *SubscriptionHandler) applyTemplate(w http.ResponseWriter, r *http.Request, templateID string, sub *model.Subscription) bool {
+	if h.templates == nil {
+		http.Error(w, `{"error": "templates are not available"}`, http.StatusBadRequest)
+		return false
+	}
+
+	tmpl, err := h.templates.GetByServiceName(r.Context(), templateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTemplateNotFound) {
+			http.Error(w, `{"error": "unknown template_id"}`, http.StatusBadRequest)
+			return false
+		}
+		slog.Error("Get template failed", "template_id", templateID, "error", err)
+		writeRepoError(w, err, "failed to load template")
+		return false
+	}
+
+	sub.ServiceName = tmpl.ServiceName
+	sub.Price = tmpl.DefaultPrice
+	return true
+}
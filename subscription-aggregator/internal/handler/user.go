@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/repository"
+)
+
+func WithUsers(users repository.UserRepository) Option {
+	return func(h *SubscriptionHandler) {
+		h.users = users
+	}
+}
+
+func (h *SubscriptionHandler) registerUserRoutes(mux *http.ServeMux) {
+	if h.users == nil {
+		return
+	}
+	mux.HandleFunc("POST /users", h.timeout(h.routeTimeout, h.knownParams(h.CreateUser)))
+	// Deleting a user also erases their subscription history, so it is admin-only.
+	mux.HandleFunc("DELETE /users/{user_id}", h.timeout(h.routeTimeout, h.knownParams(h.requireAdmin(h.DeleteUser))))
+}
+
+// CreateUser godoc
+// @Summary      Create user
+// @Description  Register a user ID; subscriptions can only be created for registered users
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      model.User  true  "User to create"
+// @Success      201   {object}  model.User
+// @Failure      400   {object}  model.ErrorResponse  "Invalid body or user ID"
+// @Failure      409   {object}  model.ErrorResponse  "User already exists"
+// @Failure      500   {object}  model.ErrorResponse
+// @Router       /users [post]
+func (h *SubscriptionHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req model.User
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validateUserID(req.ID); err != nil {
+		http.Error(w, `{"error": "id must be a valid, non-nil UUID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.users.Create(r.Context(), req.ID); err != nil {
+		if errors.Is(err, repository.ErrUserExists) {
+			http.Error(w, `{"error": "user already exists"}`, http.StatusConflict)
+			return
+		}
+		slog.Error("Create user failed", "id", req.ID, "error", err)
+		writeRepoError(w, err, "failed to create user")
+		return
+	}
+
+	writeMutation(w, r, http.StatusCreated, req)
+}
+
+// DeleteUser godoc
+// @Summary      Delete user
+// @Description  Delete a user together with all of their subscriptions, including deleted ones. Requires the admin token.
+// @Tags         users
+// @Param        user_id  path  string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  model.ErrorResponse  "Invalid user ID"
+// @Failure      401  {object}  model.ErrorResponse  "Missing admin token"
+// @Failure      403  {object}  model.ErrorResponse  "Wrong admin token"
+// @Failure      404  {object}  model.ErrorResponse  "User not found"
+// @Failure      500  {object}  model.ErrorResponse
+// @Router       /users/{user_id} [delete]
+func (h *SubscriptionHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("user_id")
+	if err := validateUserID(id); err != nil {
+		http.Error(w, `{"error": "invalid user ID format"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.users.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			http.Error(w, `{"error": "user not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Error("Delete user failed", "id", id, "error", err)
+		writeRepoError(w, err, "failed to delete user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"subscription-aggregator/internal/repository"
+	"subscription-aggregator/internal/repository/repotest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type memUsers map[string]bool
+
+func (m memUsers) Create(ctx context.Context, id string) error {
+	if m[id] {
+		return repository.ErrUserExists
+	}
+	m[id] = true
+	return nil
+}
+
+func (m memUsers) Delete(ctx context.Context, id string) error {
+	if !m[id] {
+		return repository.ErrUserNotFound
+	}
+	delete(m, id)
+	return nil
+}
+
+func TestUsers(t *testing.T) {
+	users := memUsers{}
+	h := NewSubscriptionHandler(repotest.NewFaultyRepo(nil), WithUsers(users), WithAdminToken("s3cret"))
+	id := uuid.NewString()
+	create := func(body string) *httptest.ResponseRecorder {
+		return serve(h, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body)))
+	}
+	remove := func(id string) *httptest.ResponseRecorder {
+		r := adminRequest("/users/"+id, "s3cret")
+		r.Method = http.MethodDelete
+		return serve(h, r)
+	}
+
+	rec := create(`{"id":"` + id + `"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"id":"`+id+`"}`, rec.Body.String())
+	assert.True(t, users[id])
+
+	assert.Equal(t, http.StatusConflict, create(`{"id":"`+id+`"}`).Code)
+	for _, body := range []string{`{}`, `{"id":"nope"}`, `{"id":"` + uuid.Nil.String() + `"}`, `nope`} {
+		assert.Equal(t, http.StatusBadRequest, create(body).Code, body)
+	}
+
+	anonymous := serve(h, httptest.NewRequest(http.MethodDelete, "/users/"+id, nil))
+	assert.Equal(t, http.StatusUnauthorized, anonymous.Code, "deleting users requires the admin token")
+	assert.True(t, users[id])
+
+	assert.Equal(t, http.StatusNoContent, remove(id).Code)
+	assert.False(t, users[id])
+	assert.Equal(t, http.StatusNotFound, remove(id).Code)
+	assert.Equal(t, http.StatusBadRequest, remove("nope").Code)
+}
+
+func TestUsers_DisabledWithoutRepo(t *testing.T) {
+	h := NewSubscriptionHandler(repotest.NewFaultyRepo(nil))
+
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"id":"`+uuid.NewString()+`"}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCreateSubscription_UnknownUser(t *testing.T) {
+	repo := repotest.NewFaultyRepo(nil).FailOn("Create", repository.ErrUserNotFound)
+	h := NewSubscriptionHandler(repo)
+
+	body := `{"service_name":"Spotify","price":300,"user_id":"` + uuid.NewString() + `","start_date":"07-2025"}`
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":"user not found"}`, rec.Body.String())
+}
@@ -1,53 +1,217 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"net/http"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
 
 	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
 )
 
-var monthYearRegex = regexp.MustCompile(`^(0[1-9]|1[0-2])-\d{4}$`)
+// maxServiceNameLen bounds service_name in characters, not bytes, so names
+// in non-Latin scripts get the same room as ASCII ones.
+const maxServiceNameLen = 255
 
-func ValidateSubscriptionInput(serviceName string, price int, userID, startDate string) error {
-	if serviceName == "" {
-		return fmt.Errorf("service_name is required")
+const maxMetadataBytes = 4096
+
+// errEndDateWithoutStart is reported as 422: the body is well-formed but
+// describes a subscription that ends without ever starting.
+var errEndDateWithoutStart = errors.New("end_date requires start_date to be set")
+
+func ValidateSubscriptionInput(serviceName string, price int, userID string, startDate monthdate.MonthDate, endDate *monthdate.MonthDate) error {
+	// Checked first so a body carrying only end_date gets a precise error
+	// rather than one about the first missing field.
+	if endDate != nil && startDate.IsZero() {
+		return errEndDateWithoutStart
+	}
+	if err := validateServiceName(serviceName); err != nil {
+		return err
 	}
 	if price <= 0 {
 		return fmt.Errorf("price must be a positive integer")
 	}
-	if _, err := uuid.Parse(userID); err != nil {
+	if err := validateUserID(userID); err != nil {
+		return err
+	}
+	if startDate.IsZero() {
+		return fmt.Errorf("start_date must be in MM-YYYY format (e.g., 07-2025)")
+	}
+	return nil
+}
+
+func validateServiceName(serviceName string) error {
+	if serviceName == "" {
+		return fmt.Errorf("service_name is required")
+	}
+	if utf8.RuneCountInString(serviceName) > maxServiceNameLen {
+		return fmt.Errorf("service_name must be at most %d characters", maxServiceNameLen)
+	}
+	if containsControl(serviceName) {
+		return fmt.Errorf("service_name must not contain control characters")
+	}
+	if strings.ContainsFunc(serviceName, isFormat) {
+		return fmt.Errorf("service_name must not contain invisible formatting characters")
+	}
+	if strings.ContainsAny(serviceName, "<>") {
+		return fmt.Errorf("service_name must not contain HTML markup")
+	}
+	return nil
+}
+
+func validateUserID(userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
 		return fmt.Errorf("user_id must be a valid UUID")
 	}
-	if !monthYearRegex.MatchString(startDate) {
+	if id == uuid.Nil {
+		return fmt.Errorf("user_id must not be the nil UUID")
+	}
+	return nil
+}
+
+func containsControl(s string) bool {
+	return strings.ContainsFunc(s, unicode.IsControl)
+}
+
+func isFormat(r rune) bool {
+	return unicode.Is(unicode.Cf, r)
+}
+
+// sanitizeServiceName drops invisible formatting characters (zero-width
+// spaces, bidi overrides) and surrounding spaces that would otherwise make
+// two names look identical but store differently.
+func sanitizeServiceName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if isFormat(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Trim(s, " ")
+}
+
+// validateSubscription sanitizes and normalizes service_name to NFC before
+// validating so decomposed accents (letter + combining mark) are accepted and
+// stored consistently.
+func validateSubscription(sub *model.Subscription) error {
+	sub.ServiceName = norm.NFC.String(sanitizeServiceName(sub.ServiceName))
+	if err := ValidateSubscriptionInput(sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate); err != nil {
+		return err
+	}
+	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
+		return fmt.Errorf("end_date must be >= start_date")
+	}
+	if sub.SLAUptimePct != nil && (*sub.SLAUptimePct < 0 || *sub.SLAUptimePct > 100) {
+		return fmt.Errorf("sla_uptime_pct must be between 0 and 100")
+	}
+	// Incidents are only recorded through the sla-incident endpoint.
+	sub.LastIncidentAt, sub.IncidentCount = nil, 0
+	return validateMetadata(sub)
+}
+
+// validatePartialSubscription applies the validateSubscription rules to the
+// fields present in a PATCH body only.
+func validatePartialSubscription(p *model.PartialSubscription) error {
+	if p.ServiceName != nil {
+		name := norm.NFC.String(sanitizeServiceName(*p.ServiceName))
+		if err := validateServiceName(name); err != nil {
+			return err
+		}
+		p.ServiceName = &name
+	}
+	if p.Price != nil && *p.Price <= 0 {
+		return fmt.Errorf("price must be a positive integer")
+	}
+	if p.UserID != nil {
+		if err := validateUserID(*p.UserID); err != nil {
+			return err
+		}
+	}
+	if p.StartDate != nil && p.StartDate.IsZero() {
 		return fmt.Errorf("start_date must be in MM-YYYY format (e.g., 07-2025)")
 	}
+	if p.EndDate != nil && p.EndDate.IsZero() {
+		return fmt.Errorf("end_date must be in MM-YYYY format (e.g., 12-2025)")
+	}
+	if p.StartDate != nil && p.EndDate != nil && p.EndDate.Before(*p.StartDate) {
+		return fmt.Errorf("end_date must be >= start_date")
+	}
+	if p.SLAUptimePct != nil && (*p.SLAUptimePct < 0 || *p.SLAUptimePct > 100) {
+		return fmt.Errorf("sla_uptime_pct must be between 0 and 100")
+	}
+	if p.Metadata != nil && string(p.Metadata) != "null" {
+		return validateMetadataObject(p.Metadata)
+	}
 	return nil
 }
 
-func ValidatePeriodDate(dateStr string) error {
-	if !monthYearRegex.MatchString(dateStr) {
-		return fmt.Errorf("date must be in MM-YYYY format")
+// validatePatchedDates checks end_date >= start_date when a patch changes only
+// one of them, taking the other from the stored subscription.
+func validatePatchedDates(p *model.PartialSubscription, current *model.Subscription) error {
+	start, end := current.StartDate, current.EndDate
+	if p.StartDate != nil {
+		start = *p.StartDate
+	}
+	if p.EndDate != nil {
+		end = p.EndDate
+	}
+	if end != nil && end.Before(start) {
+		return fmt.Errorf("end_date must be >= start_date")
 	}
 	return nil
 }
 
-func isEndDateAfterOrEqual(start, end string) bool {
-	startParts := strings.Split(start, "-")
-	endParts := strings.Split(end, "-")
+// validateMetadata accepts a JSON object up to maxMetadataBytes; an explicit
+// null is treated the same as omitting the field.
+func validateMetadata(sub *model.Subscription) error {
+	if len(sub.Metadata) == 0 || string(sub.Metadata) == "null" {
+		sub.Metadata = nil
+		return nil
+	}
+	return validateMetadataObject(sub.Metadata)
+}
+
+func validateMetadataObject(raw json.RawMessage) error {
+	if len(raw) > maxMetadataBytes {
+		return fmt.Errorf("metadata must not exceed %d bytes", maxMetadataBytes)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return fmt.Errorf("metadata must be a JSON object")
+	}
+	return nil
+}
 
-	startYear, _ := strconv.Atoi(startParts[1])
-	startMonth, _ := strconv.Atoi(startParts[0])
-	endYear, _ := strconv.Atoi(endParts[1])
-	endMonth, _ := strconv.Atoi(endParts[0])
+// validationStatus maps a validateSubscription error to its response status.
+func validationStatus(err error) int {
+	if errors.Is(err, errEndDateWithoutStart) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
 
-	if endYear > startYear {
-		return true
+// decodeSubscription decodes a model.Subscription or model.PartialSubscription body.
+func decodeSubscription(r *http.Request, sub any) error {
+	if err := json.NewDecoder(r.Body).Decode(sub); err != nil {
+		if errors.Is(err, monthdate.ErrInvalidFormat) {
+			return err
+		}
+		return fmt.Errorf("invalid JSON")
 	}
-	if endYear == startYear && endMonth >= startMonth {
-		return true
+	return nil
+}
+
+func ValidatePeriodDate(dateStr string) error {
+	if _, err := monthdate.Parse(dateStr); err != nil {
+		return fmt.Errorf("date must be in MM-YYYY format")
 	}
-	return false
+	return nil
 }
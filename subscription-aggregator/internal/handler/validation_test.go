@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSubscription_ControlCharacters(t *testing.T) {
+	for _, name := range []string{"Spotify\nPremium", "Spotify\tPremium", "Spotify\r", "Net\x00flix", "Net\u0085flix"} {
+		sub := &model.Subscription{ServiceName: name, Price: 300, UserID: uuid.New().String(), StartDate: monthdate.New(2025, time.July)}
+		err := validateSubscription(sub)
+		require.Error(t, err, "%q", name)
+		assert.Equal(t, "service_name must not contain control characters", err.Error())
+	}
+}
+
+func TestValidateServiceName(t *testing.T) {
+	for _, name := range []string{"Tidal™", "Disney+ #1", "@Home", "Netflix 🎬", "Яндекс Плюс", "'; DROP TABLE subscriptions; --", strings.Repeat("é", maxServiceNameLen)} {
+		assert.NoError(t, validateServiceName(name), "%q", name)
+	}
+
+	err := validateServiceName(strings.Repeat("a", maxServiceNameLen+1))
+	require.Error(t, err)
+	assert.Equal(t, "service_name must be at most 255 characters", err.Error())
+
+	err = validateServiceName("Net\u200bflix")
+	require.Error(t, err)
+	assert.Equal(t, "service_name must not contain invisible formatting characters", err.Error())
+}
+
+func TestValidateSubscription_NormalizesUnicode(t *testing.T) {
+	sub := &model.Subscription{ServiceName: "Cafe\u0301 Premium", Price: 300, UserID: uuid.New().String(), StartDate: monthdate.New(2025, time.July)}
+
+	require.NoError(t, validateSubscription(sub))
+	assert.Equal(t, "Caf\u00e9 Premium", sub.ServiceName)
+}
+
+func TestSanitizeServiceName(t *testing.T) {
+	assert.Equal(t, "Spotify Premium", sanitizeServiceName("  Spo\u200btify\u202e Premium\ufeff "))
+	assert.Equal(t, "Spotify\r", sanitizeServiceName("Spotify\r"), "control characters are left for validation to reject")
+}
+
+func TestCreateSubscription_ServiceNameSanitized(t *testing.T) {
+	h := NewSubscriptionHandler(&createCounter{})
+
+	body := `{"service_name":" Net\u200bflix ","price":500,"user_id":"` + uuid.NewString() + `","start_date":"07-2025"}`
+	rec := serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created model.Subscription
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	assert.Equal(t, "Netflix", created.ServiceName)
+
+	body = `{"service_name":"<script>alert(1)</script>","price":500,"user_id":"` + uuid.NewString() + `","start_date":"07-2025"}`
+	rec = serve(h, httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "service_name must not contain HTML markup")
+}
+
+func TestValidateSubscription_Metadata(t *testing.T) {
+	newSub := func(meta string) *model.Subscription {
+		return &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: uuid.New().String(),
+			StartDate: monthdate.New(2025, time.July), Metadata: json.RawMessage(meta)}
+	}
+
+	sub := newSub(`{"external_id": "abc", "flags": [1, 2]}`)
+	require.NoError(t, validateSubscription(sub))
+
+	sub = newSub(`null`)
+	require.NoError(t, validateSubscription(sub))
+	assert.Nil(t, sub.Metadata)
+
+	for _, meta := range []string{`[1, 2]`, `"text"`, `42`} {
+		err := validateSubscription(newSub(meta))
+		require.Error(t, err, meta)
+		assert.Equal(t, "metadata must be a JSON object", err.Error())
+	}
+
+	big := `{"blob": "` + strings.Repeat("x", maxMetadataBytes) + `"}`
+	err := validateSubscription(newSub(big))
+	require.Error(t, err)
+	assert.Equal(t, "metadata must not exceed 4096 bytes", err.Error())
+}
+
+func TestValidateSubscriptionInput_EndDateWithoutStartDate(t *testing.T) {
+	end := monthdate.New(2025, time.December)
+
+	err := ValidateSubscriptionInput("Spotify", 300, uuid.New().String(), monthdate.MonthDate{}, &end)
+	require.ErrorIs(t, err, errEndDateWithoutStart)
+	assert.Equal(t, http.StatusUnprocessableEntity, validationStatus(err))
+
+	err = ValidateSubscriptionInput("Spotify", 300, uuid.New().String(), monthdate.MonthDate{}, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, validationStatus(err), "a missing start_date alone stays a 400")
+}
+
+func TestValidateSubscriptionInput_NilUserID(t *testing.T) {
+	err := ValidateSubscriptionInput("Spotify", 300, uuid.Nil.String(), monthdate.New(2025, time.July), nil)
+	require.Error(t, err)
+	assert.Equal(t, "user_id must not be the nil UUID", err.Error())
+	assert.Equal(t, http.StatusBadRequest, validationStatus(err))
+}
+
+func TestUpdateSubscription_NilID(t *testing.T) {
+	h := NewSubscriptionHandler(&createCounter{})
+	body := `{"service_name":"Spotify","price":300,"user_id":"` + uuid.NewString() + `","start_date":"07-2025"}`
+
+	rec := serve(h, httptest.NewRequest(http.MethodPut, "/subscriptions/"+uuid.Nil.String(), strings.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "nil UUID")
+}
+
+func TestValidateSubscription_SLAUptime(t *testing.T) {
+	pct := func(v float64) *float64 { return &v }
+	for _, v := range []float64{-1, 100.5} {
+		sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: uuid.New().String(),
+			StartDate: monthdate.New(2025, time.July), SLAUptimePct: pct(v)}
+		err := validateSubscription(sub)
+		require.Error(t, err, v)
+		assert.Equal(t, "sla_uptime_pct must be between 0 and 100", err.Error())
+	}
+
+	now := time.Now()
+	sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: uuid.New().String(),
+		StartDate: monthdate.New(2025, time.July), SLAUptimePct: pct(99.95), LastIncidentAt: &now, IncidentCount: 4}
+	require.NoError(t, validateSubscription(sub))
+	assert.Nil(t, sub.LastIncidentAt, "incidents are not writable")
+	assert.Zero(t, sub.IncidentCount)
+}
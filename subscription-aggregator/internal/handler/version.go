@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+
+	"subscription-aggregator/internal/model"
+)
+
+const apiVersion = "v1"
+
+// Version serves build information. It is registered next to the health
+// probes and needs no authentication.
+func Version(serverVersion, commit string) http.HandlerFunc {
+	info := model.VersionInfo{APIVersion: apiVersion, ServerVersion: serverVersion, Commit: commit}
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, info)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apptime "subscription-aggregator/internal/time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Version("1.2.3", "abc123")(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"api_version":"v1","server_version":"1.2.3","commit":"abc123"}`, rec.Body.String())
+}
+
+func TestEnvelopeMiddleware(t *testing.T) {
+	clock := apptime.NewFakeClock(time.Date(2025, time.July, 1, 12, 0, 0, 0, time.UTC))
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /version", Version("1.2.3", "abc123"))
+	mux.HandleFunc("GET /subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]int{"total": 3})
+	})
+	mux.HandleFunc("POST /subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusCreated, []string{"a"})
+	})
+	mux.HandleFunc("GET /missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "subscription not found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /export", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("a,b\n"))
+	})
+	mux.HandleFunc("DELETE /subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := EnvelopeMiddleware("1.2.3", "abc123", clock)(mux)
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/subscriptions")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"api_version":"v1","server_version":"1.2.3","commit":"abc123",
+		"timestamp":"2025-07-01T12:00:00Z","data":{"total":3}}`, rec.Body.String())
+
+	rec = do(http.MethodPost, "/subscriptions")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.JSONEq(t, `{"api_version":"v1","server_version":"1.2.3","commit":"abc123",
+		"timestamp":"2025-07-01T12:00:00Z","data":["a"]}`, rec.Body.String())
+
+	rec = do(http.MethodGet, "/version")
+	assert.JSONEq(t, `{"api_version":"v1","server_version":"1.2.3","commit":"abc123"}`, rec.Body.String(),
+		"/version stays plain JSON")
+
+	rec = do(http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error": "subscription not found"}`, rec.Body.String(), "errors are not wrapped")
+
+	rec = do(http.MethodGet, "/export")
+	assert.Equal(t, "a,b\n", rec.Body.String(), "non-JSON bodies are not wrapped")
+
+	rec = do(http.MethodDelete, "/subscriptions")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,69 @@
+// Package ids generates and parses subscription ids. Both schemes are 128-bit
+// values, so the database keeps storing every id in a UUID column and only
+// the text form handed to clients differs.
+package ids
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+type Scheme string
+
+const (
+	UUID Scheme = "uuid"
+	// ULID ids sort by creation time, both as text and in the database.
+	ULID Scheme = "ulid"
+)
+
+const DefaultScheme = UUID
+
+func ParseScheme(s string) (Scheme, error) {
+	switch Scheme(s) {
+	case UUID, ULID:
+		return Scheme(s), nil
+	}
+	return "", fmt.Errorf("unknown id scheme %q, want uuid or ulid", s)
+}
+
+// New generates a fresh id.
+func (s Scheme) New() uuid.UUID {
+	if s == ULID {
+		return uuid.UUID(ulid.Make())
+	}
+	return uuid.New()
+}
+
+// Parse reads an id written in the scheme's format. The ULID scheme also
+// accepts UUIDs, so ids handed out before switching schemes keep working.
+func (s Scheme) Parse(id string) (uuid.UUID, error) {
+	if s == ULID {
+		if u, err := ulid.ParseStrict(id); err == nil {
+			return uuid.UUID(u), nil
+		}
+	}
+	return uuid.Parse(id)
+}
+
+// Format renders a stored id in the scheme's format.
+func (s Scheme) Format(id uuid.UUID) string {
+	if s == ULID {
+		return ulid.ULID(id).String()
+	}
+	return id.String()
+}
+
+// Reformat renders an id scanned from the database as text in the scheme's
+// format.
+func (s Scheme) Reformat(id string) string {
+	if s != ULID {
+		return id
+	}
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return id
+	}
+	return s.Format(u)
+}
@@ -0,0 +1,59 @@
+package ids
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheme(t *testing.T) {
+	for _, s := range []string{"uuid", "ulid"} {
+		scheme, err := ParseScheme(s)
+		require.NoError(t, err)
+		assert.Equal(t, Scheme(s), scheme)
+	}
+	for _, s := range []string{"", "UUID", "snowflake"} {
+		_, err := ParseScheme(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestUUID(t *testing.T) {
+	id := UUID.New()
+	text := UUID.Format(id)
+	assert.Len(t, text, 36)
+
+	parsed, err := UUID.Parse(text)
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+
+	_, err = UUID.Parse(ULID.Format(id))
+	assert.Error(t, err, "the uuid scheme rejects ULIDs")
+	_, err = UUID.Parse("not-an-id")
+	assert.Error(t, err)
+	assert.Equal(t, text, UUID.Reformat(text))
+}
+
+func TestULID(t *testing.T) {
+	first, second := ULID.New(), ULID.New()
+	text := ULID.Format(first)
+	assert.Len(t, text, 26)
+	assert.Less(t, text, ULID.Format(second), "ULIDs sort by creation time")
+
+	parsed, err := ULID.Parse(text)
+	require.NoError(t, err)
+	assert.Equal(t, first, parsed)
+
+	legacy := uuid.New()
+	parsed, err = ULID.Parse(legacy.String())
+	require.NoError(t, err, "UUIDs issued before switching stay valid")
+	assert.Equal(t, legacy, parsed)
+	assert.Equal(t, ULID.Format(legacy), ULID.Reformat(legacy.String()))
+
+	for _, bad := range []string{"", "not-an-id", "01ARZ3NDEKTSV4RRFFQ69G5FA", "81ARZ3NDEKTSV4RRFFQ69G5FAV"} {
+		_, err := ULID.Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	EventCreated   = "created"
+	EventCancelled = "cancelled"
+
+	OtherServiceLabel = "other"
+
+	defaultMaxServiceLabels = 50
+)
+
+var SubscriptionEvents = NewEventCounter(defaultMaxServiceLabels)
+
+func init() {
+	prometheus.MustRegister(SubscriptionEvents)
+}
+
+// EventCounter counts business events per service, bucketing every service
+// beyond the first maxServices distinct names into "other" to bound cardinality.
+type EventCounter struct {
+	vec         *prometheus.CounterVec
+	maxServices int
+
+	mu       sync.Mutex
+	services map[string]struct{}
+}
+
+func NewEventCounter(maxServices int) *EventCounter {
+	return &EventCounter{
+		vec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subscription_events_total",
+			Help: "Subscription business events by service name and event type.",
+		}, []string{"service_name", "event"}),
+		maxServices: maxServices,
+		services:    make(map[string]struct{}),
+	}
+}
+
+func (c *EventCounter) Inc(serviceName, event string) {
+	c.vec.WithLabelValues(c.serviceLabel(serviceName), event).Inc()
+}
+
+func (c *EventCounter) serviceLabel(serviceName string) string {
+	label := strings.ToLower(strings.TrimSpace(serviceName))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.services[label]; ok {
+		return label
+	}
+	if len(c.services) >= c.maxServices {
+		return OtherServiceLabel
+	}
+	c.services[label] = struct{}{}
+	return label
+}
+
+func (c *EventCounter) Describe(ch chan<- *prometheus.Desc) {
+	c.vec.Describe(ch)
+}
+
+func (c *EventCounter) Collect(ch chan<- prometheus.Metric) {
+	c.vec.Collect(ch)
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventCounter_Labels(t *testing.T) {
+	c := NewEventCounter(10)
+
+	c.Inc("Spotify", EventCreated)
+	c.Inc("spotify ", EventCreated)
+	c.Inc("Spotify", EventCancelled)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.vec.WithLabelValues("spotify", EventCreated)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.vec.WithLabelValues("spotify", EventCancelled)))
+}
+
+func TestEventCounter_CardinalityCap(t *testing.T) {
+	c := NewEventCounter(2)
+
+	c.Inc("Spotify", EventCreated)
+	c.Inc("Netflix", EventCreated)
+	c.Inc("Yandex Plus", EventCreated)
+	c.Inc("Kinopoisk", EventCreated)
+	c.Inc("Netflix", EventCreated)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.vec.WithLabelValues("spotify", EventCreated)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.vec.WithLabelValues("netflix", EventCreated)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.vec.WithLabelValues(OtherServiceLabel, EventCreated)))
+	assert.Equal(t, 3, testutil.CollectAndCount(c))
+}
@@ -0,0 +1,23 @@
+package model
+
+type CostAnomaly struct {
+	CurrentMonthCost int `json:"current_month_cost"`
+
+	ThreeMonthAvg float64 `json:"three_month_avg"`
+
+	IsAnomaly bool `json:"is_anomaly"`
+
+	DeviationPct *float64 `json:"deviation_pct"`
+}
+
+type PriceAnomaly struct {
+	ID string `json:"id"`
+
+	ServiceName string `json:"service_name"`
+
+	Price int `json:"price"`
+
+	MedianPrice float64 `json:"median_price"`
+
+	Ratio float64 `json:"ratio"`
+}
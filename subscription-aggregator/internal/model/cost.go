@@ -0,0 +1,22 @@
+package model
+
+// CostSummary reports whether any subscription matched the filter, so a
+// zero Total can be told apart from "no data for this period".
+type CostSummary struct {
+	Total int `json:"total" example:"3588"`
+
+	Count int `json:"count" example:"2"`
+
+	Matched bool `json:"matched" example:"true"`
+
+	Items []CostItem `json:"items,omitempty"`
+}
+
+// CostItem is one subscription's share of a CostSummary total.
+type CostItem struct {
+	ID string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+
+	ServiceName string `json:"service_name" example:"Spotify"`
+
+	Contribution int `json:"contribution" example:"299"`
+}
@@ -0,0 +1,11 @@
+package model
+
+type SubscriptionCounts struct {
+	Total int `json:"total"`
+
+	Active int `json:"active"`
+
+	Expired int `json:"expired"`
+
+	OpenEnded int `json:"open_ended"`
+}
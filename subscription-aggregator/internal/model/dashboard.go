@@ -0,0 +1,7 @@
+package model
+
+type UserDashboard struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+
+	TotalCost CostSummary `json:"total_cost"`
+}
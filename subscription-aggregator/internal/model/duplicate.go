@@ -0,0 +1,14 @@
+package model
+
+const (
+	DuplicateReasonOverlapping = "same_service_overlapping_dates"
+	DuplicateReasonSimilarName = "similar_name"
+)
+
+type Duplicate struct {
+	ID string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+
+	Similarity float64 `json:"similarity" example:"0.82"`
+
+	Reason string `json:"reason" example:"similar_name"`
+}
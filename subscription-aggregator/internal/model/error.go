@@ -0,0 +1,6 @@
+package model
+
+// ErrorResponse documents the {"error": "..."} body of failed requests.
+type ErrorResponse struct {
+	Error string `json:"error" example:"subscription not found"`
+}
@@ -0,0 +1,18 @@
+package model
+
+type ImportError struct {
+	Line int `json:"line"`
+
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	Created int `json:"created"`
+
+	Updated int `json:"updated"`
+
+	Failed int `json:"failed"`
+
+	// Errors lists the first failures only; Failed holds the full count.
+	Errors []ImportError `json:"errors"`
+}
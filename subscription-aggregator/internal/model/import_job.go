@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+type ImportJob struct {
+	ID string `json:"id"`
+
+	Status string `json:"status" example:"running"`
+
+	// Result is set once the job has finished; a failed job keeps the rows
+	// imported before the failure.
+	Result *ImportResult `json:"result,omitempty"`
+
+	Error string `json:"error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
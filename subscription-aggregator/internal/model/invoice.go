@@ -0,0 +1,20 @@
+package model
+
+import "subscription-aggregator/internal/monthdate"
+
+type InvoiceItem struct {
+	ServiceName string `json:"service_name"`
+
+	// Subscriptions is how many of the user's subscriptions to the service bill this month.
+	Subscriptions int `json:"subscriptions"`
+
+	Amount int `json:"amount"`
+}
+
+type InvoiceEstimate struct {
+	Month monthdate.MonthDate `json:"month"`
+
+	Total int `json:"total"`
+
+	Items []InvoiceItem `json:"items"`
+}
@@ -0,0 +1,43 @@
+package model
+
+type Pagination struct {
+	Total   int  `json:"total" example:"42"`
+	Limit   int  `json:"limit" example:"20"`
+	Offset  int  `json:"offset" example:"0"`
+	HasNext bool `json:"has_next" example:"true"`
+	HasPrev bool `json:"has_prev" example:"false"`
+}
+
+type PaginatedResponse[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+func NewPaginatedResponse[T any](data []T, total, limit, offset int) PaginatedResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+	return PaginatedResponse[T]{
+		Data: data,
+		Pagination: Pagination{
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+			HasNext: offset+len(data) < total,
+			HasPrev: offset > 0,
+		},
+	}
+}
+
+type CursorPage[T any] struct {
+	Data []T `json:"data"`
+	// NextCursor fetches the following page; empty on the last page.
+	NextCursor string `json:"next_cursor" example:"OGYxNGU0NWYtY2VlYS00NjdmLWE4ZjQtN2I5YTFjMmQzZTRm"`
+}
+
+func NewCursorPage[T any](data []T, nextCursor string) CursorPage[T] {
+	if data == nil {
+		data = []T{}
+	}
+	return CursorPage[T]{Data: data, NextCursor: nextCursor}
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaginatedResponse(t *testing.T) {
+	page := NewPaginatedResponse(make([]int, 20), 100, 20, 40)
+	assert.Equal(t, Pagination{Total: 100, Limit: 20, Offset: 40, HasNext: true, HasPrev: true}, page.Pagination)
+
+	first := NewPaginatedResponse(make([]int, 20), 30, 20, 0)
+	assert.True(t, first.Pagination.HasNext)
+	assert.False(t, first.Pagination.HasPrev)
+
+	last := NewPaginatedResponse(make([]int, 10), 30, 20, 20)
+	assert.False(t, last.Pagination.HasNext)
+	assert.True(t, last.Pagination.HasPrev)
+
+	empty := NewPaginatedResponse[int](nil, 0, 20, 0)
+	assert.NotNil(t, empty.Data)
+	assert.False(t, empty.Pagination.HasNext)
+}
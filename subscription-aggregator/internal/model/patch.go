@@ -0,0 +1,25 @@
+package model
+
+import (
+	"encoding/json"
+
+	"subscription-aggregator/internal/monthdate"
+)
+
+// PartialSubscription is a PATCH body: fields left out are not changed.
+// Sending metadata as null clears it.
+type PartialSubscription struct {
+	ServiceName *string `json:"service_name,omitempty" example:"Spotify"`
+
+	Price *int `json:"price,omitempty" example:"349"`
+
+	UserID *string `json:"user_id,omitempty" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+
+	StartDate *monthdate.MonthDate `json:"start_date,omitempty" swaggertype:"string" example:"07-2025"`
+
+	EndDate *monthdate.MonthDate `json:"end_date,omitempty" swaggertype:"string" example:"12-2025"`
+
+	Metadata json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
+
+	SLAUptimePct *float64 `json:"sla_uptime_pct,omitempty" example:"99.9"`
+}
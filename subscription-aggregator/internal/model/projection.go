@@ -0,0 +1,26 @@
+package model
+
+import "subscription-aggregator/internal/monthdate"
+
+type ProjectedSubscription struct {
+	ID string `json:"id"`
+
+	ServiceName string `json:"service_name"`
+
+	MonthlyPrice int `json:"monthly_price"`
+
+	// Months is how many of the projected months the subscription stays active.
+	Months int `json:"months"`
+
+	ProjectedCost int `json:"projected_cost"`
+}
+
+type AnnualProjection struct {
+	From monthdate.MonthDate `json:"from"`
+
+	To monthdate.MonthDate `json:"to"`
+
+	ProjectedAnnualCost int `json:"projected_annual_cost"`
+
+	Subscriptions []ProjectedSubscription `json:"subscriptions"`
+}
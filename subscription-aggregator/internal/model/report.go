@@ -0,0 +1,24 @@
+package model
+
+import "subscription-aggregator/internal/monthdate"
+
+// ServiceCost is one service's share of a CostReport.
+type ServiceCost struct {
+	ServiceName string `json:"service_name" example:"Spotify"`
+
+	Subscriptions int `json:"subscriptions" example:"1"`
+
+	Amount int `json:"amount" example:"3588"`
+}
+
+type CostReport struct {
+	UserID string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+
+	From monthdate.MonthDate `json:"from" swaggertype:"string" example:"01-2025"`
+
+	To monthdate.MonthDate `json:"to" swaggertype:"string" example:"12-2025"`
+
+	Total int `json:"total" example:"3588"`
+
+	Services []ServiceCost `json:"services"`
+}
@@ -0,0 +1,20 @@
+package model
+
+type RetentionPoint struct {
+	// MonthsAfter counts from the cohort month, which is 0.
+	MonthsAfter int `json:"months_after"`
+
+	Month string `json:"month" example:"07-2025"`
+
+	Active int `json:"active"`
+
+	Rate float64 `json:"rate"`
+}
+
+type Retention struct {
+	CohortMonth string `json:"cohort_month" example:"01-2025"`
+
+	CohortSize int `json:"cohort_size"`
+
+	Points []RetentionPoint `json:"points"`
+}
@@ -0,0 +1,13 @@
+package model
+
+type AnnualSavings struct {
+	AnnualSavings int `json:"annual_savings"`
+
+	MonthlyCost int `json:"monthly_cost"`
+
+	AnnualPrice int `json:"annual_price"`
+
+	SavingsPct float64 `json:"savings_pct"`
+
+	RecommendAnnual bool `json:"recommend_annual"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"subscription-aggregator/internal/monthdate"
+)
+
+type ShareLink struct {
+	ShareURL string `json:"share_url"`
+
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SharedSubscription is the read-only view served to share link holders; it
+// omits the owner's user_id.
+type SharedSubscription struct {
+	ServiceName string `json:"service_name"`
+
+	Price int `json:"price"`
+
+	StartDate monthdate.MonthDate `json:"start_date"`
+
+	EndDate *monthdate.MonthDate `json:"end_date,omitempty"`
+}
@@ -1,15 +1,59 @@
 package model
 
+import (
+	"encoding/json"
+	"time"
+
+	"subscription-aggregator/internal/monthdate"
+)
+
 type Subscription struct {
-	ID string `json:"id"`
+	ID string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+
+	ServiceName string `json:"service_name" example:"Spotify"`
+
+	Price int `json:"price" example:"299"`
+
+	UserID string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+
+	StartDate monthdate.MonthDate `json:"start_date" swaggertype:"string" example:"07-2025"`
+
+	EndDate *monthdate.MonthDate `json:"end_date,omitempty" swaggertype:"string" example:"12-2025"`
 
-	ServiceName string `json:"service_name"`
+	Metadata json.RawMessage `json:"metadata,omitempty" swaggertype:"object"`
 
-	Price int `json:"price"`
+	SLAUptimePct *float64 `json:"sla_uptime_pct,omitempty" example:"99.9"`
 
-	UserID string `json:"user_id"`
+	// LastIncidentAt and IncidentCount are maintained by
+	// POST /subscriptions/{id}/sla-incident and ignored on write.
+	LastIncidentAt *time.Time `json:"last_incident_at,omitempty"`
 
-	StartDate string `json:"start_date"`
+	IncidentCount int `json:"incident_count,omitempty"`
+
+	// DeletedAt is set by DELETE /subscriptions/{id} and ignored on write.
+	// Deleted subscriptions are only listed with include_deleted=true.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// CreatedAt and UpdatedAt are set by the database and ignored on write.
+	CreatedAt time.Time `json:"created_at" example:"2025-07-01T12:00:00Z"`
+
+	UpdatedAt time.Time `json:"updated_at" example:"2025-07-01T12:00:00Z"`
+}
+
+// SubscriptionWithDerived is a Subscription as returned by the read
+// endpoints, with values computed at request time rather than stored.
+type SubscriptionWithDerived struct {
+	Subscription
+
+	// ActiveMonths counts start_date through end_date, or through the
+	// current month while the subscription is open-ended.
+	ActiveMonths int `json:"active_months" example:"6"`
+}
 
-	EndDate *string `json:"end_date,omitempty"`
+func NewSubscriptionWithDerived(sub Subscription, current monthdate.MonthDate) SubscriptionWithDerived {
+	end := current
+	if sub.EndDate != nil {
+		end = *sub.EndDate
+	}
+	return SubscriptionWithDerived{Subscription: sub, ActiveMonths: max(monthdate.MonthsBetween(sub.StartDate, end), 0)}
 }
@@ -0,0 +1,13 @@
+package model
+
+type Template struct {
+	ServiceName string `json:"service_name"`
+
+	DefaultPrice int `json:"default_price"`
+
+	DefaultBillingCycle string `json:"default_billing_cycle"`
+
+	Category string `json:"category"`
+
+	LogoURL *string `json:"logo_url,omitempty"`
+}
@@ -0,0 +1,13 @@
+package model
+
+type TerraformState struct {
+	Resources []TerraformResource `json:"resources"`
+}
+
+type TerraformResource struct {
+	Type string `json:"type"`
+
+	Name string `json:"name"`
+
+	Values Subscription `json:"values"`
+}
@@ -0,0 +1,11 @@
+package model
+
+type ServiceUsage struct {
+	ServiceName string `json:"service_name"`
+
+	Subscriptions int `json:"subscriptions"`
+
+	Users int `json:"users"`
+
+	AvgPrice int `json:"avg_price"`
+}
@@ -0,0 +1,5 @@
+package model
+
+type User struct {
+	ID string `json:"id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type VersionInfo struct {
+	APIVersion string `json:"api_version"`
+
+	ServerVersion string `json:"server_version"`
+
+	Commit string `json:"commit"`
+}
+
+// Envelope wraps successful JSON responses when the response envelope is
+// enabled. Data holds the body the endpoint would otherwise return.
+type Envelope struct {
+	APIVersion string `json:"api_version"`
+
+	ServerVersion string `json:"server_version"`
+
+	Commit string `json:"commit"`
+
+	Timestamp time.Time `json:"timestamp"`
+
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
@@ -0,0 +1,135 @@
+package monthdate
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrInvalidFormat = errors.New("date must be in MM-YYYY format")
+
+// MinYear and MaxYear bound the years Parse accepts. Every month the API or
+// the repository reads goes through Parse, so they all share this range.
+const (
+	MinYear = 1900
+	MaxYear = 2100
+)
+
+// MonthDate is a calendar month stored as "MM-YYYY" text in the database and JSON.
+type MonthDate struct {
+	Year  int
+	Month time.Month
+}
+
+func New(year int, month time.Month) MonthDate {
+	return MonthDate{Year: year, Month: month}
+}
+
+func FromTime(t time.Time) MonthDate {
+	return MonthDate{Year: t.Year(), Month: t.Month()}
+}
+
+func Parse(s string) (MonthDate, error) {
+	if len(s) != 7 || s[2] != '-' || !isDigits(s[0:2]) || !isDigits(s[3:7]) {
+		return MonthDate{}, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+	}
+	month, _ := strconv.Atoi(s[0:2])
+	year, _ := strconv.Atoi(s[3:7])
+	if month < 1 || month > 12 {
+		return MonthDate{}, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+	}
+	if year < MinYear || year > MaxYear {
+		return MonthDate{}, fmt.Errorf("%w: %q: year must be between %d and %d", ErrInvalidFormat, s, MinYear, MaxYear)
+	}
+	return MonthDate{Year: year, Month: time.Month(month)}, nil
+}
+
+func (d MonthDate) String() string {
+	return fmt.Sprintf("%02d-%04d", int(d.Month), d.Year)
+}
+
+func (d MonthDate) IsZero() bool {
+	return d.Year == 0 && d.Month == 0
+}
+
+func (d MonthDate) Compare(other MonthDate) int {
+	if c := cmp.Compare(d.Year, other.Year); c != 0 {
+		return c
+	}
+	return cmp.Compare(d.Month, other.Month)
+}
+
+func (d MonthDate) Before(other MonthDate) bool {
+	return d.Compare(other) < 0
+}
+
+func (d MonthDate) After(other MonthDate) bool {
+	return d.Compare(other) > 0
+}
+
+func (d MonthDate) AddMonths(n int) MonthDate {
+	total := d.Year*12 + int(d.Month) - 1 + n
+	return MonthDate{Year: total / 12, Month: time.Month(total%12 + 1)}
+}
+
+// MonthsBetween counts the calendar months from..to with both ends included,
+// so a single month is 1. It is zero or negative when to is before from.
+func MonthsBetween(from, to MonthDate) int {
+	return (to.Year-from.Year)*12 + int(to.Month-from.Month) + 1
+}
+
+func (d MonthDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *MonthDate) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, data)
+	}
+	if s == nil || *s == "" {
+		*d = MonthDate{}
+		return nil
+	}
+	parsed, err := Parse(*s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// ScanText and TextValue let pgx read and write MonthDate directly from TEXT columns.
+func (d *MonthDate) ScanText(v pgtype.Text) error {
+	if !v.Valid {
+		*d = MonthDate{}
+		return nil
+	}
+	parsed, err := Parse(v.String)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d MonthDate) TextValue() (pgtype.Text, error) {
+	if d.IsZero() {
+		return pgtype.Text{}, nil
+	}
+	return pgtype.Text{String: d.String(), Valid: true}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
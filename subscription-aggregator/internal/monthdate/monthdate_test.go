@@ -0,0 +1,80 @@
+package monthdate
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	d, err := Parse("07-2025")
+	require.NoError(t, err)
+	assert.Equal(t, New(2025, time.July), d)
+	assert.Equal(t, "07-2025", d.String())
+
+	for _, s := range []string{"", "7-2025", "13-2025", "00-2025", "07/2025", "2025-07", "07-20x5", "+7-2025",
+		"07-0000", "12-1899", "01-2101", "07-9999"} {
+		_, err := Parse(s)
+		assert.ErrorIs(t, err, ErrInvalidFormat, s)
+	}
+	for _, s := range []string{"01-1900", "12-2100"} {
+		_, err := Parse(s)
+		assert.NoError(t, err, s)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	dec24, jan25, feb25 := New(2024, time.December), New(2025, time.January), New(2025, time.February)
+
+	assert.True(t, dec24.Before(jan25))
+	assert.True(t, feb25.After(jan25))
+	assert.Equal(t, 0, jan25.Compare(New(2025, time.January)))
+}
+
+func TestAddMonths(t *testing.T) {
+	assert.Equal(t, New(2025, time.February), New(2024, time.December).AddMonths(2))
+	assert.Equal(t, New(2024, time.December), New(2025, time.January).AddMonths(-1))
+	assert.Equal(t, New(2026, time.January), New(2025, time.January).AddMonths(12))
+}
+
+func TestMonthsBetween(t *testing.T) {
+	jan25 := New(2025, time.January)
+
+	assert.Equal(t, 1, MonthsBetween(jan25, jan25))
+	assert.Equal(t, 12, MonthsBetween(jan25, New(2025, time.December)))
+	assert.Equal(t, 2, MonthsBetween(New(2024, time.December), jan25))
+	assert.Equal(t, 0, MonthsBetween(jan25, New(2024, time.December)))
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Start MonthDate  `json:"start"`
+		End   *MonthDate `json:"end"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"start":"03-2025","end":null}`), &v))
+	assert.Equal(t, New(2025, time.March), v.Start)
+	assert.Nil(t, v.End)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"start":"03-2025","end":null}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"start":"2025-03"}`), &v)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestText(t *testing.T) {
+	var d MonthDate
+	require.NoError(t, d.ScanText(pgtype.Text{String: "11-2024", Valid: true}))
+	assert.Equal(t, New(2024, time.November), d)
+
+	v, err := d.TextValue()
+	require.NoError(t, err)
+	assert.Equal(t, pgtype.Text{String: "11-2024", Valid: true}, v)
+
+	assert.ErrorIs(t, d.ScanText(pgtype.Text{String: "bad", Valid: true}), ErrInvalidFormat)
+}
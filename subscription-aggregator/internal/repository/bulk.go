@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const DefaultBulkInsertThreshold = 10
+
+// WithBulkInsertThreshold sets the batch size above which BulkInsert switches
+// from one INSERT per row to COPY.
+func WithBulkInsertThreshold(n int) RepoOption {
+	return func(o *repoOptions) {
+		o.bulkInsertThreshold = n
+	}
+}
+
+// subscriptionCopyColumns leaves out created_at and updated_at so COPY
+// stamps them with the database defaults, as INSERT does.
+var subscriptionCopyColumns = []string{
+	"id", "service_name", "price", "user_id", "start_date", "end_date", "metadata", "sla_uptime_pct",
+}
+
+// subscriptionCopySource feeds subscriptions to COPY one row at a time.
+type subscriptionCopySource struct {
+	subs []*model.Subscription
+	ids  []uuid.UUID
+	i    int
+}
+
+func (s *subscriptionCopySource) Next() bool {
+	s.i++
+	return s.i <= len(s.subs)
+}
+
+func (s *subscriptionCopySource) Values() ([]any, error) {
+	sub := s.subs[s.i-1]
+	return []any{
+		s.ids[s.i-1], sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate, sub.Metadata, sub.SLAUptimePct,
+	}, nil
+}
+
+func (s *subscriptionCopySource) Err() error { return nil }
+
+// BulkInsert creates all subs, or none of them. Batches larger than the bulk
+// insert threshold are loaded with COPY, which is much faster for big batches
+// but cannot return generated columns, so ids are generated client-side and
+// the timestamps are read back afterwards.
+func (r *PostgresSubscriptionRepo) BulkInsert(ctx context.Context, subs []*model.Subscription) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	for _, sub := range subs {
+		if _, err := uuid.Parse(sub.UserID); err != nil {
+			return fmt.Errorf("invalid user_id UUID: %w", err)
+		}
+		if sub.StartDate.IsZero() {
+			return fmt.Errorf("start_date must be in MM-YYYY format")
+		}
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	var err error
+	if len(subs) > r.bulkInsertThreshold {
+		err = r.copySubscriptions(ctx, subs)
+	} else {
+		err = r.batchInsertSubscriptions(ctx, subs)
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if isForeignKeyViolation(err) {
+			return ErrUserNotFound
+		}
+		if isCheckViolation(err) {
+			return ErrInvalidDateRange
+		}
+		slog.Error("Failed to bulk insert subscriptions", "count", len(subs), "error", err)
+		return fmt.Errorf("database insert failed: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresSubscriptionRepo) copySubscriptions(ctx context.Context, subs []*model.Subscription) error {
+	src := &subscriptionCopySource{subs: subs, ids: make([]uuid.UUID, len(subs))}
+	for i := range subs {
+		src.ids[i] = r.idScheme.New()
+	}
+	conn := r.txOrConn(ctx)
+	if _, err := conn.CopyFrom(ctx, pgx.Identifier{"subscriptions"}, subscriptionCopyColumns, src); err != nil {
+		return err
+	}
+
+	for i, sub := range subs {
+		sub.ID = r.idScheme.Format(src.ids[i])
+	}
+
+	// The rows are stored once COPY returns, so failing to read the
+	// timestamps back must not report the insert as failed.
+	if err := readTimestamps(ctx, conn, subs, src.ids); err != nil {
+		slog.Warn("Failed to read back bulk inserted timestamps", "count", len(subs), "error", err)
+	}
+	return nil
+}
+
+func readTimestamps(ctx context.Context, conn DBTX, subs []*model.Subscription, ids []uuid.UUID) error {
+	rows, err := conn.Query(ctx, `SELECT id, created_at, updated_at FROM subscriptions WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type stamps struct{ created, updated time.Time }
+	byID := make(map[uuid.UUID]stamps, len(subs))
+	for rows.Next() {
+		var id uuid.UUID
+		var s stamps
+		if err := rows.Scan(&id, &s.created, &s.updated); err != nil {
+			return err
+		}
+		byID[id] = s
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, sub := range subs {
+		sub.CreatedAt, sub.UpdatedAt = byID[ids[i]].created, byID[ids[i]].updated
+	}
+	return nil
+}
+
+// batchInsertSubscriptions sends one INSERT per row in an implicit
+// transaction, so a failing row rolls back the whole batch.
+func (r *PostgresSubscriptionRepo) batchInsertSubscriptions(ctx context.Context, subs []*model.Subscription) error {
+	batch := &pgx.Batch{}
+	newIDs := make([]uuid.UUID, len(subs))
+	for i, sub := range subs {
+		newIDs[i] = r.idScheme.New()
+		batch.Queue(`
+			INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date, metadata, sla_uptime_pct)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING created_at, updated_at`,
+			newIDs[i], sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate, sub.Metadata, sub.SLAUptimePct)
+	}
+
+	results := r.txOrConn(ctx).SendBatch(ctx, batch)
+	defer results.Close()
+
+	created := make([]time.Time, len(subs))
+	updated := make([]time.Time, len(subs))
+	for i := range subs {
+		if err := results.QueryRow().Scan(&created[i], &updated[i]); err != nil {
+			return err
+		}
+	}
+	if err := results.Close(); err != nil {
+		return err
+	}
+	for i, sub := range subs {
+		sub.ID = r.idScheme.Format(newIDs[i])
+		sub.CreatedAt, sub.UpdatedAt = created[i], updated[i]
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the last row of a Paginate page. Clients only ever see it
+// through EncodeCursor, so the keyset can change without breaking them.
+type Cursor struct {
+	StartDate monthdate.MonthDate `json:"start_date"`
+	ID        string              `json:"id"`
+}
+
+// EncodeCursor renders c as base64url JSON, safe to pass in a query string.
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.StartDate.IsZero() {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
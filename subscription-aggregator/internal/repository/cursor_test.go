@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{StartDate: monthdate.New(2025, time.March), ID: "8f14e45f-ceea-467f-a8f4-7b9a1c2d3e4f"}
+	encoded := EncodeCursor(c)
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"start_date":"03-2025","id":"8f14e45f-ceea-467f-a8f4-7b9a1c2d3e4f"}`, string(raw))
+
+	got, err := DecodeCursor(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, c, got)
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, bad := range []string{
+		"",
+		"!!!",
+		encode(`not json`),
+		encode(`{"id":"8f14e45f-ceea-467f-a8f4-7b9a1c2d3e4f"}`),
+		encode(`{"start_date":"2025-03","id":"8f14e45f-ceea-467f-a8f4-7b9a1c2d3e4f"}`),
+		encode(`{"start_date":"03-2025","id":"nope"}`),
+	} {
+		_, err := DecodeCursor(bad)
+		assert.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
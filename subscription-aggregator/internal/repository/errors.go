@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrNotFound = errors.New("subscription not found")
+
+// ErrDuplicate reports a write that would create a second subscription with
+// the same user_id, service_name and start_date.
+var ErrDuplicate = errors.New("subscription already exists")
+
+// ErrIDTaken reports a replace whose id belongs to a deleted subscription or
+// to another user's; the first has to go through Restore instead.
+var ErrIDTaken = errors.New("subscription id is taken")
+
+// ErrInvalidDateRange reports a write rejected by the database because
+// end_date is before start_date.
+var ErrInvalidDateRange = errors.New("end_date must be >= start_date")
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	checkViolation      = "23514"
+)
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
+
+func isCheckViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == checkViolation
+}
+
+func IsConnectionError(err error) bool {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) || errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
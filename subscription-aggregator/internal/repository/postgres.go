@@ -2,115 +2,524 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strconv"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
+	"subscription-aggregator/internal/ids"
 	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+const DefaultQueryTimeout = 30 * time.Second
+
 type PostgresSubscriptionRepo struct {
-	conn *pgx.Conn
+	conn    DBTX
+	replica DBTX
+	repoOptions
+}
+
+// repoOptions holds the settings shared by every Postgres repository. Each
+// constructor applies the RepoOptions it is given on top of the defaults.
+type repoOptions struct {
+	queryTimeout time.Duration
+	idScheme     ids.Scheme
+
+	bulkInsertThreshold int
+}
+
+type RepoOption func(*repoOptions)
+
+func newRepoOptions(opts []RepoOption) repoOptions {
+	o := repoOptions{queryTimeout: DefaultQueryTimeout, idScheme: ids.DefaultScheme, bulkInsertThreshold: DefaultBulkInsertThreshold}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithQueryTimeout bounds each repository call; zero disables the bound.
+func WithQueryTimeout(d time.Duration) RepoOption {
+	return func(o *repoOptions) {
+		o.queryTimeout = d
+	}
+}
+
+// WithIDScheme selects how new subscription ids are generated and how ids
+// are read and written, including those referenced by share links.
+func WithIDScheme(s ids.Scheme) RepoOption {
+	return func(o *repoOptions) {
+		o.idScheme = s
+	}
+}
+
+func NewPostgresSubscriptionRepo(conn DBTX, opts ...RepoOption) *PostgresSubscriptionRepo {
+	return newPostgresSubscriptionRepo(conn, nil, opts)
+}
+
+// NewPostgresSubscriptionRepoWithReplica routes reads to replica and writes to primary.
+func NewPostgresSubscriptionRepoWithReplica(primary, replica DBTX, opts ...RepoOption) *PostgresSubscriptionRepo {
+	return newPostgresSubscriptionRepo(primary, replica, opts)
+}
+
+func newPostgresSubscriptionRepo(primary, replica DBTX, opts []RepoOption) *PostgresSubscriptionRepo {
+	return &PostgresSubscriptionRepo{conn: primary, replica: replica, repoOptions: newRepoOptions(opts)}
+}
+
+// QueryContext derives a context that expires after d, or at the parent's
+// deadline if that is sooner. A non-positive d only adds cancellation.
+func QueryContext(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+type primaryKey struct{}
+
+// WithPrimary forces reads made with the returned context to hit the primary,
+// e.g. to read back a row right after writing it despite replication lag.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func (r *PostgresSubscriptionRepo) txOrConn(ctx context.Context) DBTX {
+	return txOrConn(ctx, r.conn)
 }
 
-func NewPostgresSubscriptionRepo(conn *pgx.Conn) *PostgresSubscriptionRepo {
-	return &PostgresSubscriptionRepo{conn: conn}
+// reader picks the connection for reads: the active transaction if any, so
+// reads see its uncommitted writes, then the replica unless WithPrimary is set.
+func (r *PostgresSubscriptionRepo) reader(ctx context.Context) DBTX {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	if r.replica == nil {
+		return r.conn
+	}
+	if forced, _ := ctx.Value(primaryKey{}).(bool); forced {
+		return r.conn
+	}
+	return r.replica
 }
 
 func (r *PostgresSubscriptionRepo) Create(ctx context.Context, sub *model.Subscription) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
 	if _, err := uuid.Parse(sub.UserID); err != nil {
 		return fmt.Errorf("invalid user_id UUID: %w", err)
 	}
-	if !isValidMonthYear(sub.StartDate) {
+	if sub.StartDate.IsZero() {
 		return fmt.Errorf("start_date must be in MM-YYYY format")
 	}
 
 	query := `
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id`
+		INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date, metadata, sla_uptime_pct)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at`
 
-	var id uuid.UUID
-	err := r.conn.QueryRow(ctx, query,
+	id := r.idScheme.New()
+	err := r.txOrConn(ctx).QueryRow(ctx, query,
+		id,
 		sub.ServiceName,
 		sub.Price,
 		sub.UserID,
 		sub.StartDate,
 		sub.EndDate,
-	).Scan(&id)
+		sub.Metadata,
+		sub.SLAUptimePct,
+	).Scan(&sub.CreatedAt, &sub.UpdatedAt)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if isForeignKeyViolation(err) {
+			return ErrUserNotFound
+		}
+		if isCheckViolation(err) {
+			return ErrInvalidDateRange
+		}
 		slog.Error("Failed to create subscription", "error", err)
 		return fmt.Errorf("database insert failed: %w", err)
 	}
 
-	sub.ID = id.String()
+	sub.ID = r.idScheme.Format(id)
 	slog.Debug("Subscription created", "id", sub.ID)
 	return nil
 }
 
 func (r *PostgresSubscriptionRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
-	parsedID, err := uuid.Parse(id)
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
 	if err != nil {
 		return nil, fmt.Errorf("invalid subscription ID format")
 	}
 
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
 		FROM subscriptions
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	var sub model.Subscription
-	var endDate sql.NullString
 
-	err = r.conn.QueryRow(ctx, query, parsedID).Scan(
+	err = r.reader(ctx).QueryRow(ctx, query, parsedID).Scan(
 		&sub.ID,
 		&sub.ServiceName,
 		&sub.Price,
 		&sub.UserID,
 		&sub.StartDate,
-		&endDate,
+		&sub.EndDate,
+		&sub.Metadata,
+		&sub.SLAUptimePct,
+		&sub.LastIncidentAt,
+		&sub.IncidentCount,
+		&sub.DeletedAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
 	)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("subscription not found")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
 		}
 		slog.Error("Failed to get subscription by ID", "id", id, "error", err)
 		return nil, fmt.Errorf("database query failed: %w", err)
 	}
 
-	if endDate.Valid {
-		sub.EndDate = &endDate.String
+	sub.ID = r.idScheme.Reformat(sub.ID)
+	return &sub, nil
+}
+
+// RecordAccess marks the subscription as read by a client, which keeps it
+// off ListStale. Internal reads go through GetByID and do not count.
+func (r *PostgresSubscriptionRepo) RecordAccess(ctx context.Context, id string) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid subscription ID: %w", err)
 	}
 
-	return &sub, nil
+	query := `UPDATE subscriptions SET last_accessed_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	commandTag, err := r.txOrConn(ctx).Exec(ctx, query, parsedID)
+	if err != nil {
+		slog.Error("Failed to record subscription access", "id", id, "error", err)
+		return fmt.Errorf("database update failed: %w", err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DefaultListSort is the ListByUserID order when ListOptions.Sort is empty.
+const DefaultListSort = "created_at_desc"
+
+// listSorts maps each accepted ListOptions.Sort key to its ORDER BY clause so
+// request input never reaches the SQL. id breaks ties to keep pages stable.
+var listSorts = map[string]string{
+	"created_at_asc":   "created_at ASC, id",
+	"created_at_desc":  "created_at DESC, id",
+	"price_asc":        "price ASC, id",
+	"price_desc":       "price DESC, id",
+	"start_date_asc":   startDateKey + " ASC, id",
+	"start_date_desc":  startDateKey + " DESC, id",
+	"service_name_asc": "service_name ASC, id",
+}
+
+// ListSorts returns the accepted ListOptions.Sort keys in alphabetical order.
+func ListSorts() []string {
+	keys := make([]string, 0, len(listSorts))
+	for k := range listSorts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-func (r *PostgresSubscriptionRepo) ListByUserID(ctx context.Context, userID string) ([]model.Subscription, error) {
+func (r *PostgresSubscriptionRepo) ListByUserID(
+	ctx context.Context,
+	userID string,
+	opts ListOptions,
+) ([]model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
 	if _, err := uuid.Parse(userID); err != nil {
 		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
 	}
+	if opts.ActiveOn != "" && !isValidMonthYear(opts.ActiveOn) {
+		return nil, fmt.Errorf("active_on must be in MM-YYYY format")
+	}
 
 	query := `
-		SELECT id, service_name, price, user_id, start_date, end_date
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
 		FROM subscriptions
-		WHERE user_id = $1
-		ORDER BY start_date DESC`
+		WHERE user_id = $1`
+
+	args := []any{userID}
 
-	rows, err := r.conn.Query(ctx, query, userID)
+	if !opts.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	if opts.ServiceName != "" {
+		args = append(args, opts.ServiceName)
+		query += " AND " + serviceNameCond("=", len(args), opts.CaseSensitive)
+	}
+	query, args = appendMetadataConds(query, args, opts.Metadata)
+	query, args = appendActiveOnCond(query, args, opts.ActiveOn)
+
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = DefaultListSort
+	}
+	orderBy, ok := listSorts[sortKey]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q", sortKey)
+	}
+	query += " ORDER BY " + orderBy
+
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if opts.Offset > 0 {
+		args = append(args, opts.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
 	if err != nil {
 		slog.Error("Failed to list subscriptions", "user_id", userID, "error", err)
 		return nil, fmt.Errorf("database query failed: %w", err)
 	}
+
+	return r.scanSubscriptions(rows)
+}
+
+// startDateKey sorts MM-YYYY start dates chronologically as YYYYMM text.
+// Unlike to_date it is immutable, so subscriptions_user_start_id_idx can
+// serve the keyset below.
+const startDateKey = `(right(start_date, 4) || left(start_date, 2))`
+
+// Paginate pages through a user's subscriptions by (start_date, id), oldest
+// first, starting after the given cursor (nil for the first page).
+// nextCursor is an EncodeCursor value, empty on the last page.
+func (r *PostgresSubscriptionRepo) Paginate(
+	ctx context.Context,
+	userID string,
+	after *Cursor,
+	limit int,
+) ([]model.Subscription, string, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, "", fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be a positive integer")
+	}
+
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = $1 AND deleted_at IS NULL`
+
+	args := []any{userID}
+
+	if after != nil {
+		afterID, err := r.idScheme.Parse(after.ID)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid cursor UUID: %w", err)
+		}
+		args = append(args, after.StartDate.String(), afterID)
+		query += fmt.Sprintf(" AND (%s, id) > (right($%d, 4) || left($%d, 2), $%d)",
+			startDateKey, len(args)-1, len(args)-1, len(args))
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY %s, id LIMIT $%d", startDateKey, len(args))
+
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		slog.Error("Failed to paginate subscriptions", "user_id", userID, "error", err)
+		return nil, "", fmt.Errorf("database query failed: %w", err)
+	}
+
+	subs, err := r.scanSubscriptions(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(subs) <= limit {
+		return subs, "", nil
+	}
+	subs = subs[:limit]
+	last := subs[limit-1]
+	// The cursor keeps the database form of the id, whatever the scheme.
+	lastID, err := r.idScheme.Parse(last.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid subscription ID: %w", err)
+	}
+	return subs, EncodeCursor(Cursor{StartDate: last.StartDate, ID: lastID.String()}), nil
+}
+
+func (r *PostgresSubscriptionRepo) ListStale(ctx context.Context, userID string, days int) ([]model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be a positive integer")
+	}
+
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND (last_accessed_at < NOW() - make_interval(days => $2)
+		       OR (last_accessed_at IS NULL AND created_at < NOW() - make_interval(days => $2)))
+		ORDER BY COALESCE(last_accessed_at, created_at)`
+
+	rows, err := r.reader(ctx).Query(ctx, query, userID, days)
+	if err != nil {
+		slog.Error("Failed to list stale subscriptions", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return r.scanSubscriptions(rows)
+}
+
+// serviceNameCond compares service_name against positional argument argN,
+// folding case on both sides unless caseSensitive is set.
+func serviceNameCond(op string, argN int, caseSensitive bool) string {
+	if caseSensitive {
+		return fmt.Sprintf("service_name %s $%d", op, argN)
+	}
+	return fmt.Sprintf("lower(service_name) %s lower($%d)", op, argN)
+}
+
+// appendMetadataConds adds one `metadata ->> key = value` condition per filter
+// entry, in key order so the generated SQL is stable.
+func appendMetadataConds(query string, args []any, filter map[string]string) (string, []any) {
+	keys := make([]string, 0, len(filter))
+	for k := range filter {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k, filter[k])
+		query += fmt.Sprintf(" AND metadata ->> $%d = $%d", len(args)-1, len(args))
+	}
+	return query, args
+}
+
+// appendActiveOnCond keeps subscriptions running during month, as
+// ListActiveAt does. An empty month adds nothing.
+func appendActiveOnCond(query string, args []any, month string) (string, []any) {
+	if month == "" {
+		return query, args
+	}
+	args = append(args, month)
+	n := len(args)
+	query += fmt.Sprintf(" AND to_date(start_date, 'MM-YYYY') <= to_date($%d, 'MM-YYYY')"+
+		" AND (end_date IS NULL OR to_date(end_date, 'MM-YYYY') >= to_date($%d, 'MM-YYYY'))", n, n)
+	return query, args
+}
+
+// ListExpiring returns subscriptions whose end_date falls within [from, to].
+func (r *PostgresSubscriptionRepo) ListExpiring(
+	ctx context.Context,
+	userID string,
+	from, to monthdate.MonthDate,
+) ([]model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND end_date IS NOT NULL
+		  AND to_date(end_date, 'MM-YYYY') BETWEEN to_date($2, 'MM-YYYY') AND to_date($3, 'MM-YYYY')
+		ORDER BY to_date(end_date, 'MM-YYYY'), service_name`
+
+	rows, err := r.reader(ctx).Query(ctx, query, userID, from, to)
+	if err != nil {
+		slog.Error("Failed to list expiring subscriptions", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return r.scanSubscriptions(rows)
+}
+
+// ListActiveAt returns subscriptions running during month: started on or
+// before it and not ended before it.
+func (r *PostgresSubscriptionRepo) ListActiveAt(ctx context.Context, userID, month string) ([]model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if !isValidMonthYear(month) {
+		return nil, fmt.Errorf("month must be in MM-YYYY format")
+	}
+
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND to_date(start_date, 'MM-YYYY') <= to_date($2, 'MM-YYYY')
+		  AND (end_date IS NULL OR to_date(end_date, 'MM-YYYY') >= to_date($2, 'MM-YYYY'))
+		ORDER BY service_name, to_date(start_date, 'MM-YYYY')`
+
+	rows, err := r.reader(ctx).Query(ctx, query, userID, month)
+	if err != nil {
+		slog.Error("Failed to list active subscriptions", "user_id", userID, "month", month, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return r.scanSubscriptions(rows)
+}
+
+func (r *PostgresSubscriptionRepo) scanSubscriptions(rows pgx.Rows) ([]model.Subscription, error) {
 	defer rows.Close()
 
-	var subs []model.Subscription
+	subs := make([]model.Subscription, 0)
 	for rows.Next() {
 		var sub model.Subscription
-		var endDate sql.NullString
 
 		err := rows.Scan(
 			&sub.ID,
@@ -118,131 +527,850 @@ func (r *PostgresSubscriptionRepo) ListByUserID(ctx context.Context, userID stri
 			&sub.Price,
 			&sub.UserID,
 			&sub.StartDate,
-			&endDate,
+			&sub.EndDate,
+			&sub.Metadata,
+			&sub.SLAUptimePct,
+			&sub.LastIncidentAt,
+			&sub.IncidentCount,
+			&sub.DeletedAt,
+			&sub.CreatedAt,
+			&sub.UpdatedAt,
 		)
 		if err != nil {
 			slog.Error("Failed to scan subscription row", "error", err)
 			continue
 		}
-
-		if endDate.Valid {
-			sub.EndDate = &endDate.String
-		}
+		sub.ID = r.idScheme.Reformat(sub.ID)
 
 		subs = append(subs, sub)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
 	return subs, nil
 }
 
+func (r *PostgresSubscriptionRepo) CountByUserID(ctx context.Context, userID string, opts ListOptions) (int, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return 0, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if opts.ActiveOn != "" && !isValidMonthYear(opts.ActiveOn) {
+		return 0, fmt.Errorf("active_on must be in MM-YYYY format")
+	}
+
+	query := `SELECT COUNT(*) FROM subscriptions WHERE user_id = $1`
+	args := []any{userID}
+
+	if !opts.IncludeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+
+	if opts.ServiceName != "" {
+		args = append(args, opts.ServiceName)
+		query += " AND " + serviceNameCond("=", len(args), opts.CaseSensitive)
+	}
+	query, args = appendMetadataConds(query, args, opts.Metadata)
+	query, args = appendActiveOnCond(query, args, opts.ActiveOn)
+
+	var count int
+	if err := r.reader(ctx).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		slog.Error("Failed to count subscriptions", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// Counts tallies a user's subscriptions in one pass. Active means running in
+// month now; open-ended subscriptions have no end_date whether started or not.
+func (r *PostgresSubscriptionRepo) Counts(ctx context.Context, userID string, now monthdate.MonthDate) (model.SubscriptionCounts, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return model.SubscriptionCounts{}, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+
+	query := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE to_date(start_date, 'MM-YYYY') <= to_date($2, 'MM-YYYY')
+			                   AND (end_date IS NULL OR to_date(end_date, 'MM-YYYY') >= to_date($2, 'MM-YYYY'))),
+			COUNT(*) FILTER (WHERE end_date IS NOT NULL AND to_date(end_date, 'MM-YYYY') < to_date($2, 'MM-YYYY')),
+			COUNT(*) FILTER (WHERE end_date IS NULL)
+		FROM subscriptions
+		WHERE user_id = $1 AND deleted_at IS NULL`
+
+	var c model.SubscriptionCounts
+	err := r.reader(ctx).QueryRow(ctx, query, userID, now).Scan(&c.Total, &c.Active, &c.Expired, &c.OpenEnded)
+	if err != nil {
+		slog.Error("Failed to count subscriptions by state", "user_id", userID, "error", err)
+		return model.SubscriptionCounts{}, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return c, nil
+}
+
 func (r *PostgresSubscriptionRepo) Update(ctx context.Context, id string, sub *model.Subscription) error {
-	parsedID, err := uuid.Parse(id)
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
 	if err != nil {
 		return fmt.Errorf("invalid subscription ID: %w", err)
 	}
 	if _, err := uuid.Parse(sub.UserID); err != nil {
 		return fmt.Errorf("invalid user_id UUID: %w", err)
 	}
-	if !isValidMonthYear(sub.StartDate) {
+	if sub.StartDate.IsZero() {
 		return fmt.Errorf("start_date must be in MM-YYYY format")
 	}
 
 	query := `
 		UPDATE subscriptions
-		SET service_name = $1, price = $2, user_id = $3, start_date = $4, end_date = $5
-		WHERE id = $6`
+		SET service_name = $1, price = $2, user_id = $3, start_date = $4, end_date = $5, metadata = $6,
+		    sla_uptime_pct = $7, updated_at = NOW()
+		WHERE id = $8 AND deleted_at IS NULL`
 
-	commandTag, err := r.conn.Exec(ctx, query,
+	commandTag, err := r.txOrConn(ctx).Exec(ctx, query,
 		sub.ServiceName,
 		sub.Price,
 		sub.UserID,
 		sub.StartDate,
 		sub.EndDate,
+		sub.Metadata,
+		sub.SLAUptimePct,
 		parsedID,
 	)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if isForeignKeyViolation(err) {
+			return ErrUserNotFound
+		}
+		if isCheckViolation(err) {
+			return ErrInvalidDateRange
+		}
 		slog.Error("Failed to update subscription", "id", id, "error", err)
 		return fmt.Errorf("database update failed: %w", err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("subscription not found")
+		return ErrNotFound
 	}
 
 	slog.Debug("Subscription updated", "id", id)
 	return nil
 }
 
+// patchColumns are the columns Patch may set; keys outside it are rejected so
+// the dynamic SET list can never name anything else.
+var patchColumns = map[string]bool{
+	"service_name":   true,
+	"price":          true,
+	"user_id":        true,
+	"start_date":     true,
+	"end_date":       true,
+	"metadata":       true,
+	"sla_uptime_pct": true,
+}
+
+// Patch sets only the given columns, leaving the rest of the row unchanged,
+// and returns the updated subscription. A nil value stores NULL.
+func (r *PostgresSubscriptionRepo) Patch(ctx context.Context, id string, fields map[string]any) (*model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields to patch")
+	}
+
+	columns := make([]string, 0, len(fields))
+	for col := range fields {
+		if !patchColumns[col] {
+			return nil, fmt.Errorf("column %q cannot be patched", col)
+		}
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	sets := make([]string, 0, len(columns)+1)
+	args := make([]any, 0, len(columns)+1)
+	for _, col := range columns {
+		args = append(args, fields[col])
+		sets = append(sets, fmt.Sprintf("%s = $%d", col, len(args)))
+	}
+	sets = append(sets, "updated_at = NOW()")
+	args = append(args, parsedID)
+
+	query := fmt.Sprintf(`
+		UPDATE subscriptions
+		SET %s
+		WHERE id = $%d AND deleted_at IS NULL
+		RETURNING id, service_name, price, user_id, start_date, end_date, metadata,
+		          sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at`, strings.Join(sets, ", "), len(args))
+
+	var sub model.Subscription
+	err = r.txOrConn(ctx).QueryRow(ctx, query, args...).Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.Metadata,
+		&sub.SLAUptimePct,
+		&sub.LastIncidentAt,
+		&sub.IncidentCount,
+		&sub.DeletedAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicate
+		}
+		if isForeignKeyViolation(err) {
+			return nil, ErrUserNotFound
+		}
+		if isCheckViolation(err) {
+			return nil, ErrInvalidDateRange
+		}
+		slog.Error("Failed to patch subscription", "id", id, "error", err)
+		return nil, fmt.Errorf("database update failed: %w", err)
+	}
+
+	sub.ID = r.idScheme.Reformat(sub.ID)
+	slog.Debug("Subscription patched", "id", id, "columns", columns)
+	return &sub, nil
+}
+
+func (r *PostgresSubscriptionRepo) Upsert(ctx context.Context, id string, sub *model.Subscription) (bool, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+	if _, err := uuid.Parse(sub.UserID); err != nil {
+		return false, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if sub.StartDate.IsZero() {
+		return false, fmt.Errorf("start_date must be in MM-YYYY format")
+	}
+
+	query := `
+		INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date, metadata, sla_uptime_pct)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE
+		SET service_name = EXCLUDED.service_name,
+		    price = EXCLUDED.price,
+		    user_id = EXCLUDED.user_id,
+		    start_date = EXCLUDED.start_date,
+		    end_date = EXCLUDED.end_date,
+		    metadata = EXCLUDED.metadata,
+		    sla_uptime_pct = EXCLUDED.sla_uptime_pct,
+		    updated_at = NOW()
+		WHERE subscriptions.deleted_at IS NULL AND subscriptions.user_id = EXCLUDED.user_id
+		RETURNING (xmax = 0), created_at, updated_at`
+
+	var created bool
+	err = r.txOrConn(ctx).QueryRow(ctx, query,
+		parsedID,
+		sub.ServiceName,
+		sub.Price,
+		sub.UserID,
+		sub.StartDate,
+		sub.EndDate,
+		sub.Metadata,
+		sub.SLAUptimePct,
+	).Scan(&created, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The conflicting row is deleted or belongs to another user, so
+			// the WHERE clause left it untouched.
+			return false, ErrIDTaken
+		}
+		if isUniqueViolation(err) {
+			return false, ErrDuplicate
+		}
+		if isForeignKeyViolation(err) {
+			return false, ErrUserNotFound
+		}
+		if isCheckViolation(err) {
+			return false, ErrInvalidDateRange
+		}
+		slog.Error("Failed to upsert subscription", "id", id, "error", err)
+		return false, fmt.Errorf("database upsert failed: %w", err)
+	}
+
+	sub.ID = r.idScheme.Format(parsedID)
+	slog.Debug("Subscription upserted", "id", sub.ID, "created", created)
+	return created, nil
+}
+
+// UpsertByKey inserts sub or, when the user already has the same service
+// starting in the same month, updates that row's price and end_date.
+func (r *PostgresSubscriptionRepo) UpsertByKey(ctx context.Context, sub *model.Subscription) (bool, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(sub.UserID); err != nil {
+		return false, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if sub.StartDate.IsZero() {
+		return false, fmt.Errorf("start_date must be in MM-YYYY format")
+	}
+
+	query := `
+		INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, lower(service_name), start_date) WHERE deleted_at IS NULL DO UPDATE
+		SET price = EXCLUDED.price,
+		    end_date = EXCLUDED.end_date,
+		    updated_at = NOW()
+		RETURNING id, (xmax = 0), created_at, updated_at`
+
+	var id uuid.UUID
+	var created bool
+	err := r.txOrConn(ctx).QueryRow(ctx, query,
+		r.idScheme.New(),
+		sub.ServiceName,
+		sub.Price,
+		sub.UserID,
+		sub.StartDate,
+		sub.EndDate,
+	).Scan(&id, &created, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return false, ErrUserNotFound
+		}
+		if isCheckViolation(err) {
+			return false, ErrInvalidDateRange
+		}
+		slog.Error("Failed to upsert subscription by key", "error", err)
+		return false, fmt.Errorf("database upsert failed: %w", err)
+	}
+
+	sub.ID = r.idScheme.Format(id)
+	return created, nil
+}
+
 func (r *PostgresSubscriptionRepo) Delete(ctx context.Context, id string) error {
-	parsedID, err := uuid.Parse(id)
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
 	if err != nil {
 		return fmt.Errorf("invalid subscription ID: %w", err)
 	}
 
-	query := `DELETE FROM subscriptions WHERE id = $1`
-	commandTag, err := r.conn.Exec(ctx, query, parsedID)
+	query := `UPDATE subscriptions SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	commandTag, err := r.txOrConn(ctx).Exec(ctx, query, parsedID)
 	if err != nil {
 		slog.Error("Failed to delete subscription", "id", id, "error", err)
 		return fmt.Errorf("database delete failed: %w", err)
 	}
 
 	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("subscription not found")
+		return ErrNotFound
 	}
 
 	slog.Debug("Subscription deleted", "id", id)
 	return nil
 }
 
-func (r *PostgresSubscriptionRepo) TotalCost(
-	ctx context.Context,
-	userID, serviceName, from, to string,
-) (int, error) {
-	if _, err := uuid.Parse(userID); err != nil {
-		return 0, fmt.Errorf("invalid user_id UUID: %w", err)
+// Restore undoes Delete. It returns ErrNotFound unless id names a deleted
+// subscription, and ErrDuplicate when a live subscription has since taken
+// its (user_id, service_name, start_date) key.
+func (r *PostgresSubscriptionRepo) Restore(ctx context.Context, id string) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	// Rows quarantined by check-dates stay deleted.
+	query := `
+		UPDATE subscriptions SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM subscriptions_date_quarantine q WHERE q.id = subscriptions.id)`
+	commandTag, err := r.txOrConn(ctx).Exec(ctx, query, parsedID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if isCheckViolation(err) {
+			return ErrInvalidDateRange
+		}
+		slog.Error("Failed to restore subscription", "id", id, "error", err)
+		return fmt.Errorf("database update failed: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	slog.Debug("Subscription restored", "id", id)
+	return nil
+}
+
+func (r *PostgresSubscriptionRepo) TotalCost(ctx context.Context, f CostFilter) (model.CostSummary, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(f.UserID); err != nil {
+		return model.CostSummary{}, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+
+	if !isValidMonthYear(f.From) || !isValidMonthYear(f.To) {
+		return model.CostSummary{}, fmt.Errorf("dates must be in MM-YYYY format")
 	}
 
+	where, args := costConds(f)
+	if f.Itemize {
+		return r.itemizedCost(ctx, f.UserID, where, args)
+	}
+
+	var summary model.CostSummary
+	err := r.reader(ctx).QueryRow(ctx, totalCostSelect+where, args...).Scan(&summary.Total, &summary.Count)
+	if err != nil {
+		slog.Error("Failed to calculate total cost", "user_id", f.UserID, "error", err)
+		return model.CostSummary{}, fmt.Errorf("database aggregation failed: %w", err)
+	}
+	summary.Matched = summary.Count > 0
+
+	return summary, nil
+}
+
+const totalCostSelect = "SELECT COALESCE(SUM(price), 0), COUNT(*)"
+
+// costConds builds the FROM/WHERE clause shared by the total cost queries:
+// subscriptions overlapping f.From..f.To, bound as $2 and $3.
+func costConds(f CostFilter) (string, []any) {
+	where := `
+		FROM subscriptions
+		WHERE user_id = $1 AND deleted_at IS NULL
+		  AND to_date(start_date, 'MM-YYYY') <= to_date($3, 'MM-YYYY')
+		  AND (end_date IS NULL OR to_date(end_date, 'MM-YYYY') >= to_date($2, 'MM-YYYY'))`
+
+	args := []any{f.UserID, f.From, f.To}
+
+	if f.ServiceName != "" {
+		args = append(args, f.ServiceName)
+		where += " AND " + serviceNameCond("=", len(args), f.CaseSensitive)
+	}
+	if f.ExcludeService != "" {
+		args = append(args, f.ExcludeService)
+		where += " AND " + serviceNameCond("<>", len(args), f.CaseSensitive)
+	}
+	return where, args
+}
+
+// GetUserDashboard loads all of a user's subscriptions and their total cost
+// for from..to in a single round-trip using a pgx batch.
+func (r *PostgresSubscriptionRepo) GetUserDashboard(ctx context.Context, userID, from, to string) (*model.UserDashboard, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
 	if !isValidMonthYear(from) || !isValidMonthYear(to) {
-		return 0, fmt.Errorf("dates must be in MM-YYYY format")
+		return nil, fmt.Errorf("dates must be in MM-YYYY format")
 	}
 
-	query := `
-		SELECT COALESCE(SUM(price), 0)
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
 		FROM subscriptions
-		WHERE user_id = $1
-		  AND start_date <= $3
-		  AND (end_date IS NULL OR end_date >= $2)`
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY `+startDateKey+` DESC, id`, userID)
+	where, args := costConds(CostFilter{UserID: userID, From: from, To: to})
+	batch.Queue(totalCostSelect+where, args...)
+
+	results := r.reader(ctx).SendBatch(ctx, batch)
+	defer results.Close()
 
-	args := []any{userID, from, to}
-	argIndex := 4
+	rows, err := results.Query()
+	if err != nil {
+		slog.Error("Failed to load dashboard subscriptions", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+	subs, err := r.scanSubscriptions(rows)
+	if err != nil {
+		return nil, err
+	}
 
-	if serviceName != "" {
-		query += fmt.Sprintf(" AND service_name = $%d", argIndex)
-		args = append(args, serviceName)
+	dashboard := &model.UserDashboard{Subscriptions: subs}
+	if err := results.QueryRow().Scan(&dashboard.TotalCost.Total, &dashboard.TotalCost.Count); err != nil {
+		slog.Error("Failed to load dashboard total cost", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("database aggregation failed: %w", err)
 	}
+	dashboard.TotalCost.Matched = dashboard.TotalCost.Count > 0
 
-	var total int
-	err := r.conn.QueryRow(ctx, query, args...).Scan(&total)
+	return dashboard, nil
+}
+
+// itemizedCost loads the rows behind a TotalCost query and sums them in Go,
+// so the items always add up to the reported total.
+func (r *PostgresSubscriptionRepo) itemizedCost(ctx context.Context, userID, where string, args []any) (model.CostSummary, error) {
+	rows, err := r.reader(ctx).Query(ctx, "SELECT id, service_name, price"+where+" ORDER BY service_name, id", args...)
+	if err != nil {
+		slog.Error("Failed to itemize total cost", "user_id", userID, "error", err)
+		return model.CostSummary{}, fmt.Errorf("database aggregation failed: %w", err)
+	}
+	defer rows.Close()
+
+	summary := model.CostSummary{Items: make([]model.CostItem, 0)}
+	for rows.Next() {
+		var item model.CostItem
+		if err := rows.Scan(&item.ID, &item.ServiceName, &item.Contribution); err != nil {
+			return model.CostSummary{}, fmt.Errorf("database aggregation failed: %w", err)
+		}
+		item.ID = r.idScheme.Reformat(item.ID)
+		summary.Items = append(summary.Items, item)
+		summary.Total += item.Contribution
+	}
+	if err := rows.Err(); err != nil {
+		return model.CostSummary{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+	summary.Count = len(summary.Items)
+	summary.Matched = summary.Count > 0
+
+	return summary, nil
+}
+
+const duplicateSimilarityThreshold = 0.6
+
+func (r *PostgresSubscriptionRepo) FindDuplicates(
+	ctx context.Context,
+	excludeID string,
+	sub *model.Subscription,
+) ([]model.Duplicate, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(excludeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+	if _, err := uuid.Parse(sub.UserID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if sub.StartDate.IsZero() {
+		return nil, fmt.Errorf("start_date must be in MM-YYYY format")
+	}
+
+	query := `
+		WITH candidates AS (
+			SELECT id,
+			       similarity(lower(service_name), lower($3)) AS score,
+			       lower(service_name) = lower($3)
+			         AND to_date(start_date, 'MM-YYYY') <= COALESCE(to_date($5, 'MM-YYYY'), 'infinity'::date)
+			         AND (end_date IS NULL OR to_date(end_date, 'MM-YYYY') >= to_date($4, 'MM-YYYY')) AS overlapping
+			FROM subscriptions
+			WHERE user_id = $1 AND id <> $2 AND deleted_at IS NULL
+		)
+		SELECT id, score, overlapping
+		FROM candidates
+		WHERE overlapping OR score >= $6
+		ORDER BY score DESC, id`
+
+	rows, err := r.reader(ctx).Query(ctx, query,
+		sub.UserID,
+		parsedID,
+		sub.ServiceName,
+		sub.StartDate,
+		sub.EndDate,
+		duplicateSimilarityThreshold,
+	)
 	if err != nil {
-		slog.Error("Failed to calculate total cost", "user_id", userID, "error", err)
-		return 0, fmt.Errorf("database aggregation failed: %w", err)
+		slog.Error("Failed to find duplicate subscriptions", "id", excludeID, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
 	}
+	defer rows.Close()
 
-	return total, nil
+	duplicates := make([]model.Duplicate, 0)
+	for rows.Next() {
+		var dup model.Duplicate
+		var score float32
+		var overlapping bool
+
+		if err := rows.Scan(&dup.ID, &score, &overlapping); err != nil {
+			slog.Error("Failed to scan duplicate row", "error", err)
+			continue
+		}
+		dup.ID = r.idScheme.Reformat(dup.ID)
+
+		dup.Similarity = math.Round(float64(score)*100) / 100
+		dup.Reason = model.DuplicateReasonSimilarName
+		if overlapping {
+			dup.Reason = model.DuplicateReasonOverlapping
+		}
+		duplicates = append(duplicates, dup)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return duplicates, nil
 }
 
 func isValidMonthYear(s string) bool {
-	if len(s) != 7 || s[2] != '-' {
-		return false
+	_, err := monthdate.Parse(s)
+	return err == nil
+}
+
+// ServiceUsage aggregates subscriptions across all users per service, most
+// subscribed first, and returns one page of services together with how many
+// services there are in total.
+func (r *PostgresSubscriptionRepo) ServiceUsage(ctx context.Context, limit, offset int) ([]model.ServiceUsage, int, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("limit must be a positive integer")
+	}
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("offset must not be negative")
+	}
+
+	var total int
+	if err := r.reader(ctx).QueryRow(ctx,
+		`SELECT COUNT(DISTINCT service_name) FROM subscriptions WHERE deleted_at IS NULL`).Scan(&total); err != nil {
+		slog.Error("Failed to count services", "error", err)
+		return nil, 0, fmt.Errorf("database query failed: %w", err)
+	}
+
+	query := `
+		SELECT service_name,
+		       COUNT(*) AS subscription_count,
+		       COUNT(DISTINCT user_id) AS user_count,
+		       ROUND(AVG(price))::int AS avg_price
+		FROM subscriptions
+		WHERE deleted_at IS NULL
+		GROUP BY service_name
+		ORDER BY subscription_count DESC, service_name
+		LIMIT $1 OFFSET $2`
+
+	rows, err := r.reader(ctx).Query(ctx, query, limit, offset)
+	if err != nil {
+		slog.Error("Failed to aggregate service usage", "error", err)
+		return nil, 0, fmt.Errorf("database query failed: %w", err)
 	}
-	month, err1 := strconv.Atoi(s[0:2])
-	year, err2 := strconv.Atoi(s[3:7])
-	if err1 != nil || err2 != nil {
-		return false
+	defer rows.Close()
+
+	usage := make([]model.ServiceUsage, 0)
+	for rows.Next() {
+		var u model.ServiceUsage
+		if err := rows.Scan(&u.ServiceName, &u.Subscriptions, &u.Users, &u.AvgPrice); err != nil {
+			slog.Error("Failed to scan service usage row", "error", err)
+			continue
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return usage, total, nil
+}
+
+// PriceAnomalies returns the user's subscriptions priced above multiplier
+// times the median price of the same service across all users.
+func (r *PostgresSubscriptionRepo) PriceAnomalies(ctx context.Context, userID string, multiplier float64) ([]model.PriceAnomaly, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+
+	query := `
+		WITH medians AS (
+			SELECT service_name, percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS median_price
+			FROM subscriptions
+			WHERE deleted_at IS NULL
+			GROUP BY service_name
+		)
+		SELECT s.id, s.service_name, s.price, m.median_price
+		FROM subscriptions s
+		JOIN medians m ON m.service_name = s.service_name
+		WHERE s.user_id = $1 AND s.deleted_at IS NULL
+		  AND s.price > m.median_price * $2
+		ORDER BY s.price / m.median_price DESC, s.service_name`
+
+	rows, err := r.reader(ctx).Query(ctx, query, userID, multiplier)
+	if err != nil {
+		slog.Error("Failed to find price anomalies", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+	defer rows.Close()
+
+	anomalies := make([]model.PriceAnomaly, 0)
+	for rows.Next() {
+		var a model.PriceAnomaly
+		if err := rows.Scan(&a.ID, &a.ServiceName, &a.Price, &a.MedianPrice); err != nil {
+			return nil, fmt.Errorf("scan price anomaly: %w", err)
+		}
+		a.ID = r.idScheme.Reformat(a.ID)
+		a.Ratio = float64(a.Price) / a.MedianPrice
+		anomalies = append(anomalies, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return anomalies, nil
+}
+
+// RecordIncident stamps last_incident_at with the current time and bumps the
+// incident count, returning the updated subscription.
+func (r *PostgresSubscriptionRepo) RecordIncident(ctx context.Context, id string) (*model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	query := `
+		UPDATE subscriptions
+		SET last_incident_at = NOW(), incident_count = incident_count + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id, service_name, price, user_id, start_date, end_date, metadata,
+		          sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at`
+
+	var sub model.Subscription
+	err = r.txOrConn(ctx).QueryRow(ctx, query, parsedID).Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.Metadata,
+		&sub.SLAUptimePct,
+		&sub.LastIncidentAt,
+		&sub.IncidentCount,
+		&sub.DeletedAt,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		slog.Error("Failed to record SLA incident", "id", id, "error", err)
+		return nil, fmt.Errorf("database update failed: %w", err)
+	}
+
+	sub.ID = r.idScheme.Reformat(sub.ID)
+	slog.Debug("SLA incident recorded", "id", id, "incidents", sub.IncidentCount)
+	return &sub, nil
+}
+
+// ListSLABreaches returns the user's subscriptions with at least minIncidents
+// recorded incidents, most recent incident first.
+func (r *PostgresSubscriptionRepo) ListSLABreaches(ctx context.Context, userID string, minIncidents int) ([]model.Subscription, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user_id UUID: %w", err)
+	}
+	if minIncidents < 1 {
+		return nil, fmt.Errorf("min_incidents must be a positive integer")
+	}
+
+	query := `
+		SELECT id, service_name, price, user_id, start_date, end_date, metadata,
+		       sla_uptime_pct, last_incident_at, incident_count, deleted_at, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = $1 AND incident_count >= $2 AND deleted_at IS NULL
+		ORDER BY last_incident_at DESC, service_name`
+
+	rows, err := r.reader(ctx).Query(ctx, query, userID, minIncidents)
+	if err != nil {
+		slog.Error("Failed to list SLA breaches", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return r.scanSubscriptions(rows)
+}
+
+// Retention follows the subscriptions that started in cohort across all users
+// and counts how many are still active 0..months months later.
+func (r *PostgresSubscriptionRepo) Retention(ctx context.Context, cohort monthdate.MonthDate, months int) (model.Retention, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	if cohort.IsZero() {
+		return model.Retention{}, fmt.Errorf("cohort month is required")
+	}
+	if months < 0 {
+		return model.Retention{}, fmt.Errorf("months must not be negative")
+	}
+
+	query := `
+		SELECT k,
+		       COUNT(s.id) FILTER (WHERE s.end_date IS NULL
+		           OR to_date(s.end_date, 'MM-YYYY') >= to_date($1, 'MM-YYYY') + make_interval(months => k))
+		FROM generate_series(0, $2) AS k
+		LEFT JOIN subscriptions s ON s.start_date = $1 AND s.deleted_at IS NULL
+		GROUP BY k
+		ORDER BY k`
+
+	rows, err := r.reader(ctx).Query(ctx, query, cohort, months)
+	if err != nil {
+		slog.Error("Failed to compute retention", "cohort", cohort, "error", err)
+		return model.Retention{}, fmt.Errorf("database query failed: %w", err)
+	}
+	defer rows.Close()
+
+	retention := model.Retention{CohortMonth: cohort.String(), Points: make([]model.RetentionPoint, 0, months+1)}
+	for rows.Next() {
+		var p model.RetentionPoint
+		if err := rows.Scan(&p.MonthsAfter, &p.Active); err != nil {
+			return model.Retention{}, fmt.Errorf("scan retention row: %w", err)
+		}
+		p.Month = cohort.AddMonths(p.MonthsAfter).String()
+		retention.Points = append(retention.Points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return model.Retention{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	// Everything in the cohort is active in its first month.
+	if len(retention.Points) > 0 {
+		retention.CohortSize = retention.Points[0].Active
+	}
+	for i := range retention.Points {
+		if retention.CohortSize > 0 {
+			retention.Points[i].Rate = float64(retention.Points[i].Active) / float64(retention.CohortSize)
+		}
 	}
-	return month >= 1 && month <= 12 && year >= 1900 && year <= 2100
+	return retention, nil
 }
@@ -0,0 +1,850 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-aggregator/internal/ids"
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errFakeConn = errors.New("fake connection")
+
+type fakeConn struct {
+	calls int
+}
+
+func (c *fakeConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	c.calls++
+	return pgconn.CommandTag{}, errFakeConn
+}
+
+func (c *fakeConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.calls++
+	return nil, errFakeConn
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	c.calls++
+	return fakeRow{}
+}
+
+func (c *fakeConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	c.calls++
+	return fakeBatchResults{}
+}
+
+func (c *fakeConn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	c.calls++
+	return 0, errFakeConn
+}
+
+type fakeBatchResults struct{}
+
+func (fakeBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, errFakeConn }
+func (fakeBatchResults) Query() (pgx.Rows, error)         { return nil, errFakeConn }
+func (fakeBatchResults) QueryRow() pgx.Row                { return fakeRow{} }
+func (fakeBatchResults) Close() error                     { return nil }
+
+type fakeRow struct{}
+
+func (fakeRow) Scan(dest ...any) error {
+	return errFakeConn
+}
+
+func TestReadRouting(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := &fakeConn{}, &fakeConn{}
+	repo := NewPostgresSubscriptionRepoWithReplica(primary, replica)
+
+	userID := uuid.New().String()
+	id := uuid.New().String()
+	sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: userID, StartDate: monthdate.New(2025, time.July)}
+
+	_, _ = repo.GetByID(ctx, id)
+	_, _ = repo.ListByUserID(ctx, userID, ListOptions{})
+	_, _, _ = repo.Paginate(ctx, userID, nil, 10)
+	_, _ = repo.TotalCost(ctx, CostFilter{UserID: userID, From: "01-2025", To: "12-2025"})
+	_, _ = repo.CountByUserID(ctx, userID, ListOptions{})
+	_, _ = repo.ListStale(ctx, userID, 90)
+	_, _ = repo.ListExpiring(ctx, userID, monthdate.New(2025, time.July), monthdate.New(2025, time.October))
+	_, _ = repo.FindDuplicates(ctx, id, sub)
+	_, _, _ = repo.ServiceUsage(ctx, 20, 0)
+	_, _ = repo.ListActiveAt(ctx, userID, "05-2024")
+	_, _ = repo.Counts(ctx, userID, monthdate.New(2025, time.July))
+	_, _ = repo.GetUserDashboard(ctx, userID, "01-2025", "12-2025")
+	_, _ = repo.PriceAnomalies(ctx, userID, 3)
+	_, _ = repo.ListSLABreaches(ctx, userID, 1)
+	_, _ = repo.Retention(ctx, monthdate.New(2025, time.January), 6)
+	assert.Equal(t, 15, replica.calls, "reads must hit the replica")
+	assert.Equal(t, 0, primary.calls, "reads must not hit the primary")
+
+	_ = repo.Create(ctx, sub)
+	_ = repo.Update(ctx, id, sub)
+	_, _ = repo.Patch(ctx, id, map[string]any{"price": 400})
+	_, _ = repo.Upsert(ctx, id, sub)
+	_, _ = repo.UpsertByKey(ctx, sub)
+	_ = repo.BulkInsert(ctx, []*model.Subscription{sub})
+	_ = repo.Delete(ctx, id)
+	_ = repo.Restore(ctx, id)
+	_, _ = repo.RecordIncident(ctx, id)
+	_ = repo.RecordAccess(ctx, id)
+	assert.Equal(t, 10, primary.calls, "writes must hit the primary")
+	assert.Equal(t, 15, replica.calls, "writes must not hit the replica")
+
+	_, _ = repo.GetByID(WithPrimary(ctx), id)
+	assert.Equal(t, 11, primary.calls, "forced reads must hit the primary")
+}
+
+// deadlineConn records how long the context of each query had left.
+type deadlineConn struct {
+	fakeConn
+	remaining []time.Duration
+}
+
+func (c *deadlineConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.remaining = append(c.remaining, time.Until(deadline))
+	} else {
+		c.remaining = append(c.remaining, -1)
+	}
+	return c.fakeConn.Query(ctx, sql, args...)
+}
+
+func TestQueryTimeout(t *testing.T) {
+	userID := uuid.NewString()
+
+	conn := &deadlineConn{}
+	_, _ = NewPostgresSubscriptionRepo(conn).ListByUserID(context.Background(), userID, ListOptions{})
+	_, _ = NewPostgresSubscriptionRepo(conn, WithQueryTimeout(time.Second)).ListByUserID(context.Background(), userID, ListOptions{})
+
+	short, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, _ = NewPostgresSubscriptionRepo(conn).ListByUserID(short, userID, ListOptions{})
+	_, _ = NewPostgresSubscriptionRepo(conn, WithQueryTimeout(0)).ListByUserID(context.Background(), userID, ListOptions{})
+
+	require.Len(t, conn.remaining, 4)
+	assert.InDelta(t, DefaultQueryTimeout, conn.remaining[0], float64(time.Second))
+	assert.InDelta(t, time.Second, conn.remaining[1], float64(100*time.Millisecond))
+	assert.LessOrEqual(t, conn.remaining[2], 100*time.Millisecond, "an earlier caller deadline wins")
+	assert.Equal(t, time.Duration(-1), conn.remaining[3], "zero disables the timeout")
+}
+
+func TestRepoOptions_AppliedByEveryRepository(t *testing.T) {
+	opts := []RepoOption{WithQueryTimeout(time.Second), WithIDScheme(ids.ULID)}
+	want := repoOptions{queryTimeout: time.Second, idScheme: ids.ULID, bulkInsertThreshold: DefaultBulkInsertThreshold}
+
+	assert.Equal(t, want, NewPostgresSubscriptionRepo(nil, opts...).repoOptions)
+	assert.Equal(t, want, NewPostgresUserRepo(nil, opts...).repoOptions)
+	assert.Equal(t, want, NewPostgresShareLinkRepo(nil, opts...).repoOptions)
+	assert.Equal(t, want, NewPostgresTemplateRepo(nil, opts...).repoOptions)
+}
+
+func TestQueryTimeout_OtherRepos(t *testing.T) {
+	conn := &deadlineConn{}
+	_, _ = NewPostgresTemplateRepo(conn).Search(context.Background(), "", 10)
+	_, _ = NewPostgresTemplateRepo(conn, WithQueryTimeout(time.Second)).Search(context.Background(), "", 10)
+	_, _ = NewPostgresTemplateRepo(conn, WithQueryTimeout(0)).Search(context.Background(), "", 10)
+
+	require.Len(t, conn.remaining, 3)
+	assert.InDelta(t, DefaultQueryTimeout, conn.remaining[0], float64(time.Second))
+	assert.InDelta(t, time.Second, conn.remaining[1], float64(100*time.Millisecond))
+	assert.Equal(t, time.Duration(-1), conn.remaining[2], "zero disables the timeout")
+}
+
+func TestReadRouting_NoReplica(t *testing.T) {
+	ctx := context.Background()
+	primary := &fakeConn{}
+	repo := NewPostgresSubscriptionRepo(primary)
+
+	_, _ = repo.GetByID(ctx, uuid.New().String())
+	_, _ = repo.ListByUserID(ctx, uuid.New().String(), ListOptions{})
+	assert.Equal(t, 2, primary.calls)
+}
+
+const defaultTestDSN = "host=localhost port=5433 user=testuser password=testpass dbname=testdb sslmode=disable"
+
+func connectTestDB(t *testing.T) *pgx.Conn {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		dsn = defaultTestDSN
+	}
+
+	conn, err := pgx.Connect(context.Background(), dsn)
+	if err != nil {
+		t.Skipf("test database unavailable: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(context.Background()) })
+
+	var table *string
+	require.NoError(t, conn.QueryRow(context.Background(), `SELECT to_regclass('subscriptions')::text`).Scan(&table))
+	if table == nil {
+		t.Skip("subscriptions table missing, run migrations first")
+	}
+	return conn
+}
+
+// createTestUser registers a user for test subscriptions to reference.
+// Deleting it on cleanup also removes any subscriptions left behind.
+func createTestUser(t *testing.T, conn DBTX) string {
+	t.Helper()
+	users := NewPostgresUserRepo(conn)
+	id := uuid.NewString()
+	require.NoError(t, users.Create(context.Background(), id))
+	t.Cleanup(func() { _ = users.Delete(context.Background(), id) })
+	return id
+}
+
+func TestUsers(t *testing.T) {
+	conn := connectTestDB(t)
+	users := NewPostgresUserRepo(conn)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+
+	sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: uuid.NewString(), StartDate: monthdate.New(2025, time.July)}
+	assert.ErrorIs(t, repo.Create(ctx, sub), ErrUserNotFound, "user_id must reference a user")
+
+	sub.UserID = createTestUser(t, conn)
+	assert.ErrorIs(t, users.Create(ctx, sub.UserID), ErrUserExists)
+	require.NoError(t, repo.Create(ctx, sub))
+
+	require.NoError(t, users.Delete(ctx, sub.UserID))
+	_, err := repo.GetByID(ctx, sub.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "deleting a user removes their subscriptions")
+	assert.ErrorIs(t, users.Delete(ctx, sub.UserID), ErrUserNotFound)
+}
+
+func TestRepository_ContextCancellation(t *testing.T) {
+	conn := connectTestDB(t)
+	locker := connectTestDB(t)
+
+	// Hold an exclusive lock from a second session so GetByID blocks in
+	// Postgres until its context is cancelled.
+	tx, err := locker.Begin(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tx.Rollback(context.Background()) })
+	_, err = tx.Exec(context.Background(), `LOCK TABLE subscriptions IN ACCESS EXCLUSIVE MODE`)
+	require.NoError(t, err)
+
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		_, err := repo.GetByID(ctx, uuid.New().String())
+		done <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetByID did not return after context cancellation")
+	}
+}
+
+// TestTotalCost_DateRangeParameterOrder guards the positional arguments in
+// TotalCost: $2 must bind to from and $3 to to, with filters starting at $4.
+func TestTotalCost_DateRangeParameterOrder(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+
+	sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: createTestUser(t, conn),
+		StartDate: monthdate.New(2025, time.March)}
+	require.NoError(t, repo.Create(ctx, sub))
+	t.Cleanup(func() { _ = repo.Delete(context.Background(), sub.ID) })
+
+	before, err := repo.TotalCost(ctx, CostFilter{UserID: sub.UserID, From: "01-2025", To: "02-2025"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, before.Total, "subscription starting in 03-2025 is outside 01-2025..02-2025")
+
+	within, err := repo.TotalCost(ctx, CostFilter{UserID: sub.UserID, ServiceName: "Spotify", From: "03-2025", To: "04-2025"})
+	require.NoError(t, err)
+	assert.Equal(t, 300, within.Total)
+}
+
+func TestCounts(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	end := func(m time.Month, y int) *monthdate.MonthDate {
+		d := monthdate.New(y, m)
+		return &d
+	}
+	fixture := []*model.Subscription{
+		{ServiceName: "Spotify", StartDate: monthdate.New(2024, time.January)},
+		{ServiceName: "Netflix", StartDate: monthdate.New(2024, time.March), EndDate: end(time.December, 2025)},
+		{ServiceName: "Kion", StartDate: monthdate.New(2024, time.February), EndDate: end(time.June, 2025)},
+		{ServiceName: "Yandex Plus", StartDate: monthdate.New(2025, time.September)},
+		{ServiceName: "Okko", StartDate: monthdate.New(2025, time.July), EndDate: end(time.July, 2025)},
+	}
+	for _, sub := range fixture {
+		sub.Price, sub.UserID = 300, userID
+		require.NoError(t, repo.Create(ctx, sub))
+		t.Cleanup(func() { _ = repo.Delete(context.Background(), sub.ID) })
+	}
+
+	counts, err := repo.Counts(ctx, userID, monthdate.New(2025, time.July))
+	require.NoError(t, err)
+	assert.Equal(t, model.SubscriptionCounts{Total: 5, Active: 3, Expired: 1, OpenEnded: 2}, counts)
+}
+
+func TestPriceAnomalies(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	service := "Anomaly " + uuid.NewString()
+
+	seed := func(userID string, price int) *model.Subscription {
+		sub := &model.Subscription{ServiceName: service, Price: price, UserID: userID, StartDate: monthdate.New(2025, time.January)}
+		require.NoError(t, repo.Create(ctx, sub))
+		t.Cleanup(func() { _ = repo.Delete(context.Background(), sub.ID) })
+		return sub
+	}
+	for range 3 {
+		seed(createTestUser(t, conn), 300)
+	}
+	normal := seed(createTestUser(t, conn), 310)
+	userID := createTestUser(t, conn)
+	anomalous := seed(userID, 1200)
+	seed(userID, 320)
+
+	anomalies, err := repo.PriceAnomalies(ctx, userID, 3)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, anomalous.ID, anomalies[0].ID)
+	assert.Equal(t, 300.0, anomalies[0].MedianPrice)
+	assert.InDelta(t, 4.0, anomalies[0].Ratio, 0.001)
+
+	anomalies, err = repo.PriceAnomalies(ctx, normal.UserID, 3)
+	require.NoError(t, err)
+	assert.Empty(t, anomalies)
+}
+
+func TestRetention(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+
+	// A cohort month no other test uses, since retention spans all users.
+	cohort := monthdate.New(1987, time.March)
+	end := func(months int) *monthdate.MonthDate {
+		d := cohort.AddMonths(months)
+		return &d
+	}
+	for _, e := range []*monthdate.MonthDate{nil, nil, end(3), end(1), end(0)} {
+		sub := &model.Subscription{ServiceName: "Retention", Price: 300, UserID: createTestUser(t, conn), StartDate: cohort, EndDate: e}
+		require.NoError(t, repo.Create(ctx, sub))
+		t.Cleanup(func() { _ = repo.Delete(context.Background(), sub.ID) })
+	}
+	late := &model.Subscription{ServiceName: "Retention", Price: 300, UserID: createTestUser(t, conn), StartDate: cohort.AddMonths(1)}
+	require.NoError(t, repo.Create(ctx, late))
+	t.Cleanup(func() { _ = repo.Delete(context.Background(), late.ID) })
+
+	retention, err := repo.Retention(ctx, cohort, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "03-1987", retention.CohortMonth)
+	assert.Equal(t, 5, retention.CohortSize)
+
+	var active []int
+	for _, p := range retention.Points {
+		active = append(active, p.Active)
+	}
+	assert.Equal(t, []int{5, 4, 3, 3, 2}, active)
+	assert.Equal(t, "07-1987", retention.Points[4].Month)
+	assert.InDelta(t, 0.4, retention.Points[4].Rate, 0.001)
+}
+
+func TestListByUserID_Sort(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	for _, sub := range []*model.Subscription{
+		{ServiceName: "Netflix", Price: 800, StartDate: monthdate.New(2025, time.January)},
+		{ServiceName: "Spotify", Price: 300, StartDate: monthdate.New(2024, time.December)},
+		{ServiceName: "Kion", Price: 500, StartDate: monthdate.New(2025, time.March)},
+	} {
+		sub.UserID = userID
+		require.NoError(t, repo.Create(ctx, sub))
+	}
+
+	names := func(sort string) []string {
+		subs, err := repo.ListByUserID(ctx, userID, ListOptions{Sort: sort})
+		require.NoError(t, err)
+		var got []string
+		for _, s := range subs {
+			got = append(got, s.ServiceName)
+		}
+		return got
+	}
+	assert.Equal(t, []string{"Kion", "Spotify", "Netflix"}, names(""), "most recently created first by default")
+	assert.Equal(t, []string{"Kion", "Netflix", "Spotify"}, names("start_date_desc"))
+	assert.Equal(t, []string{"Spotify", "Netflix", "Kion"}, names("start_date_asc"))
+	assert.Equal(t, []string{"Spotify", "Kion", "Netflix"}, names("price_asc"))
+	assert.Equal(t, []string{"Netflix", "Kion", "Spotify"}, names("price_desc"))
+	assert.Equal(t, []string{"Kion", "Netflix", "Spotify"}, names("service_name_asc"))
+}
+
+func TestListByUserID_UnknownSort(t *testing.T) {
+	conn := &fakeConn{}
+	repo := NewPostgresSubscriptionRepo(conn)
+
+	_, err := repo.ListByUserID(context.Background(), uuid.NewString(), ListOptions{Sort: "price; DROP TABLE subscriptions"})
+	assert.ErrorContains(t, err, "unknown sort")
+	assert.Zero(t, conn.calls, "an unknown sort must not reach the database")
+}
+
+func TestListByUserID_ActiveOn(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	apr25, may25 := monthdate.New(2025, time.April), monthdate.New(2025, time.May)
+	for _, sub := range []*model.Subscription{
+		{ServiceName: "Open", StartDate: monthdate.New(2024, time.December)},
+		{ServiceName: "Ends in May", StartDate: monthdate.New(2025, time.January), EndDate: &may25},
+		{ServiceName: "Ended", StartDate: monthdate.New(2024, time.January), EndDate: &apr25},
+		{ServiceName: "Starts later", StartDate: monthdate.New(2025, time.June)},
+	} {
+		sub.UserID, sub.Price = userID, 100
+		require.NoError(t, repo.Create(ctx, sub))
+	}
+
+	opts := ListOptions{ActiveOn: "05-2025", Sort: "service_name_asc"}
+	subs, err := repo.ListByUserID(ctx, userID, opts)
+	require.NoError(t, err)
+	var names []string
+	for _, s := range subs {
+		names = append(names, s.ServiceName)
+	}
+	assert.Equal(t, []string{"Ends in May", "Open"}, names)
+
+	count, err := repo.CountByUserID(ctx, userID, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestListByUserID_InvalidActiveOn(t *testing.T) {
+	conn := &fakeConn{}
+	repo := NewPostgresSubscriptionRepo(conn)
+
+	_, err := repo.ListByUserID(context.Background(), uuid.NewString(), ListOptions{ActiveOn: "2025-05"})
+	assert.ErrorContains(t, err, "active_on must be in MM-YYYY format")
+	assert.Zero(t, conn.calls)
+}
+
+func TestPaginate(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+
+	userID := createTestUser(t, conn)
+	// 12-2024 sorts after 01-2025 as text, so this also checks the date order.
+	starts := []monthdate.MonthDate{
+		monthdate.New(2025, time.January), monthdate.New(2024, time.December),
+		monthdate.New(2025, time.January), monthdate.New(2023, time.June), monthdate.New(2025, time.February),
+	}
+	var want []model.Subscription
+	for i, start := range starts {
+		sub := &model.Subscription{ServiceName: "Paged " + strconv.Itoa(i), Price: 100, UserID: userID, StartDate: start}
+		require.NoError(t, repo.Create(ctx, sub))
+		t.Cleanup(func() { _ = repo.Delete(context.Background(), sub.ID) })
+		want = append(want, *sub)
+	}
+	slices.SortFunc(want, func(a, b model.Subscription) int {
+		if c := a.StartDate.Compare(b.StartDate); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	var got []string
+	var after *Cursor
+	for page := 0; ; page++ {
+		require.Less(t, page, 5, "pagination did not terminate")
+		subs, next, err := repo.Paginate(ctx, userID, after, 2)
+		require.NoError(t, err)
+		for _, s := range subs {
+			got = append(got, s.ID)
+		}
+		if next == "" {
+			assert.Len(t, subs, 1, "last page holds the remainder")
+			break
+		}
+		assert.Len(t, subs, 2)
+		c, err := DecodeCursor(next)
+		require.NoError(t, err)
+		after = &c
+	}
+	var wantIDs []string
+	for _, s := range want {
+		wantIDs = append(wantIDs, s.ID)
+	}
+	assert.Equal(t, wantIDs, got)
+
+	last := want[len(want)-1]
+	subs, next, err := repo.Paginate(ctx, userID, &Cursor{StartDate: last.StartDate, ID: last.ID}, 2)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+	assert.Empty(t, next)
+}
+
+func TestSlowQueryConn_LogsSlowQuery(t *testing.T) {
+	conn := connectTestDB(t)
+
+	var buf bytes.Buffer
+	slow := NewSlowQueryConn(conn, 50*time.Millisecond)
+	slow.logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	_, err := slow.Exec(context.Background(), `SELECT pg_sleep(0.1), $1::text`, "user@example.com")
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"msg":"Slow query"`)
+	assert.Contains(t, buf.String(), `"operation":"SELECT pg_sleep(0.1), $1::text"`)
+	assert.Contains(t, buf.String(), `"arg_count":1`)
+	assert.NotContains(t, buf.String(), "user@example.com", "argument values must not be logged")
+
+	buf.Reset()
+	var one int
+	require.NoError(t, slow.QueryRow(context.Background(), `SELECT 1`).Scan(&one))
+	assert.Empty(t, buf.String(), "fast queries must not be logged")
+}
+
+type fakeTx struct {
+	pgx.Tx
+	fakeConn
+	committed, rolledBack bool
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return tx.fakeConn.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.fakeConn.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.fakeConn.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return tx.fakeConn.SendBatch(ctx, b)
+}
+
+func (tx *fakeTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return tx.fakeConn.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+type fakeBeginner struct {
+	tx *fakeTx
+}
+
+func (b fakeBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	return b.tx, nil
+}
+
+func TestTxFromContext_RoutesAllCalls(t *testing.T) {
+	primary, replica, tx := &fakeConn{}, &fakeConn{}, &fakeTx{}
+	repo := NewPostgresSubscriptionRepoWithReplica(primary, replica)
+	ctx := WithTx(context.Background(), tx)
+
+	assert.Same(t, tx, TxFromContext(ctx))
+	assert.Nil(t, TxFromContext(context.Background()))
+
+	id := uuid.New().String()
+	sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: uuid.New().String(), StartDate: monthdate.New(2025, time.July)}
+	_ = repo.Create(ctx, sub)
+	_, _ = repo.GetByID(ctx, id)
+	_, _ = repo.ListByUserID(ctx, sub.UserID, ListOptions{})
+	_ = repo.Delete(ctx, id)
+
+	assert.Equal(t, 4, tx.calls)
+	assert.Zero(t, primary.calls)
+	assert.Zero(t, replica.calls)
+}
+
+func TestInTx(t *testing.T) {
+	t.Run("commits on success", func(t *testing.T) {
+		tx := &fakeTx{}
+		err := InTx(context.Background(), fakeBeginner{tx}, func(ctx context.Context) error {
+			assert.Same(t, tx, TxFromContext(ctx))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, tx.committed)
+		assert.False(t, tx.rolledBack)
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		tx := &fakeTx{}
+		err := InTx(context.Background(), fakeBeginner{tx}, func(ctx context.Context) error {
+			return errFakeConn
+		})
+		require.ErrorIs(t, err, errFakeConn)
+		assert.False(t, tx.committed)
+		assert.True(t, tx.rolledBack)
+	})
+
+	t.Run("joins an outer transaction", func(t *testing.T) {
+		outer, inner := &fakeTx{}, &fakeTx{}
+		ctx := WithTx(context.Background(), outer)
+		err := InTx(ctx, fakeBeginner{inner}, func(ctx context.Context) error {
+			assert.Same(t, outer, TxFromContext(ctx))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.False(t, inner.committed)
+		assert.False(t, outer.committed)
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	sub := &model.Subscription{ServiceName: "Deleted", Price: 100, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+	require.NoError(t, repo.Create(ctx, sub))
+	require.NoError(t, repo.Delete(ctx, sub.ID))
+
+	_, err := repo.GetByID(ctx, sub.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, sub.ID), ErrNotFound, "a deleted subscription cannot be deleted again")
+	subs, err := repo.ListByUserID(ctx, userID, ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+
+	subs, err = repo.ListByUserID(ctx, userID, ListOptions{IncludeDeleted: true})
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.NotNil(t, subs[0].DeletedAt)
+
+	// The deleted row no longer holds its unique key...
+	again := &model.Subscription{ServiceName: "Deleted", Price: 200, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+	require.NoError(t, repo.Create(ctx, again))
+	// ...so restoring it would duplicate the new one.
+	assert.ErrorIs(t, repo.Restore(ctx, sub.ID), ErrDuplicate)
+
+	require.NoError(t, repo.Delete(ctx, again.ID))
+	require.NoError(t, repo.Restore(ctx, sub.ID))
+	got, err := repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 100, got.Price)
+	assert.Nil(t, got.DeletedAt)
+	assert.ErrorIs(t, repo.Restore(ctx, sub.ID), ErrNotFound, "a live subscription cannot be restored")
+}
+
+func TestUniqueKey_CaseInsensitive(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	sub := &model.Subscription{ServiceName: "Spotify", Price: 300, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+	require.NoError(t, repo.Create(ctx, sub))
+
+	variant := &model.Subscription{ServiceName: "spotify", Price: 300, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+	assert.ErrorIs(t, repo.Create(ctx, variant), ErrDuplicate)
+
+	upsert := &model.Subscription{ServiceName: "SPOTIFY", Price: 500, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+	created, err := repo.UpsertByKey(ctx, upsert)
+	require.NoError(t, err)
+	assert.False(t, created, "a case variant updates the existing subscription")
+	assert.Equal(t, sub.ID, upsert.ID)
+
+	got, err := repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Spotify", got.ServiceName)
+	assert.Equal(t, 500, got.Price)
+}
+
+func TestTimestamps(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+
+	sub := &model.Subscription{ServiceName: "Timestamped", Price: 100, UserID: createTestUser(t, conn), StartDate: monthdate.New(2025, time.March)}
+	require.NoError(t, repo.Create(ctx, sub))
+	assert.False(t, sub.CreatedAt.IsZero(), "created_at is set on insert")
+	assert.Equal(t, sub.CreatedAt, sub.UpdatedAt)
+
+	sub.Price = 150
+	require.NoError(t, repo.Update(ctx, sub.ID, sub))
+	got, err := repo.GetByID(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.True(t, got.CreatedAt.Equal(sub.CreatedAt), "created_at does not change")
+	assert.True(t, got.UpdatedAt.After(got.CreatedAt), "updated_at moves on update")
+}
+
+func TestDateRangeCheck(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	_, err := conn.Exec(ctx, `
+		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
+		VALUES ('Inverted', 100, $1, '03-2025', '12-2024')`, userID)
+	assert.True(t, isCheckViolation(err), "the database rejects an inverted range: %v", err)
+
+	end := monthdate.New(2024, time.December)
+	sub := &model.Subscription{ServiceName: "Inverted", Price: 100, UserID: userID, StartDate: monthdate.New(2025, time.March), EndDate: &end}
+	assert.ErrorIs(t, repo.Create(ctx, sub), ErrInvalidDateRange)
+
+	sub.EndDate = nil
+	require.NoError(t, repo.Create(ctx, sub))
+	_, err = repo.Patch(ctx, sub.ID, map[string]any{"end_date": end})
+	assert.ErrorIs(t, err, ErrInvalidDateRange)
+
+	var deletedID string
+	require.NoError(t, conn.QueryRow(ctx, `
+		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date, deleted_at)
+		VALUES ('Inverted history', 100, $1, '03-2025', '12-2024', NOW()) RETURNING id::text`, userID).Scan(&deletedID),
+		"deleted rows are exempt")
+	assert.ErrorIs(t, repo.Restore(ctx, deletedID), ErrInvalidDateRange, "restoring re-checks the range")
+}
+
+// copyConn records whether BulkInsert went through COPY or a batch.
+type copyConn struct {
+	fakeConn
+	copied, batched bool
+}
+
+func (c *copyConn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	c.copied = true
+	return 0, errFakeConn
+}
+
+func (c *copyConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	c.batched = true
+	return fakeBatchResults{}
+}
+
+func TestBulkInsert_Threshold(t *testing.T) {
+	newSubs := func(n int) []*model.Subscription {
+		subs := make([]*model.Subscription, n)
+		for i := range subs {
+			subs[i] = &model.Subscription{ServiceName: "Bulk", Price: 100, UserID: uuid.NewString(), StartDate: monthdate.New(2025, time.March)}
+		}
+		return subs
+	}
+
+	conn := &copyConn{}
+	_ = NewPostgresSubscriptionRepo(conn, WithBulkInsertThreshold(3)).BulkInsert(context.Background(), newSubs(3))
+	assert.True(t, conn.batched)
+	assert.False(t, conn.copied)
+
+	conn = &copyConn{}
+	_ = NewPostgresSubscriptionRepo(conn, WithBulkInsertThreshold(3)).BulkInsert(context.Background(), newSubs(4))
+	assert.True(t, conn.copied)
+	assert.False(t, conn.batched)
+}
+
+func TestBulkInsert(t *testing.T) {
+	conn := connectTestDB(t)
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	for _, n := range []int{2, 12} {
+		repo := NewPostgresSubscriptionRepo(conn)
+		subs := make([]*model.Subscription, n)
+		for i := range subs {
+			subs[i] = &model.Subscription{ServiceName: fmt.Sprintf("Bulk %d-%d", n, i), Price: 100 + i, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+		}
+		require.NoError(t, repo.BulkInsert(ctx, subs))
+
+		for _, sub := range subs {
+			require.NotEmpty(t, sub.ID)
+			assert.False(t, sub.CreatedAt.IsZero())
+			got, err := repo.GetByID(ctx, sub.ID)
+			require.NoError(t, err)
+			assert.Equal(t, sub.ServiceName, got.ServiceName)
+			assert.Equal(t, sub.Price, got.Price)
+			assert.True(t, sub.CreatedAt.Equal(got.CreatedAt), "timestamps come from the database")
+		}
+
+		// The duplicate sits after a fresh row, which must not survive it.
+		batch := make([]*model.Subscription, n)
+		for i := range batch {
+			batch[i] = &model.Subscription{ServiceName: fmt.Sprintf("Bulk %d-retry-%d", n, i), Price: 1, UserID: userID, StartDate: subs[0].StartDate}
+		}
+		fresh := batch[0]
+		batch[n-1].ServiceName = subs[0].ServiceName
+		assert.ErrorIs(t, repo.BulkInsert(ctx, batch), ErrDuplicate)
+		count, err := repo.CountByUserID(ctx, userID, ListOptions{ServiceName: fresh.ServiceName, CaseSensitive: true})
+		require.NoError(t, err)
+		assert.Zero(t, count, "a failing row rolls back the whole batch")
+	}
+}
+
+func TestIDScheme_ULID(t *testing.T) {
+	conn := connectTestDB(t)
+	repo := NewPostgresSubscriptionRepo(conn, WithIDScheme(ids.ULID))
+	ctx := context.Background()
+	userID := createTestUser(t, conn)
+
+	var created []string
+	for _, name := range []string{"First", "Second", "Third"} {
+		sub := &model.Subscription{ServiceName: name, Price: 100, UserID: userID, StartDate: monthdate.New(2025, time.March)}
+		require.NoError(t, repo.Create(ctx, sub))
+		_, err := ids.ULID.Parse(sub.ID)
+		require.NoError(t, err)
+		assert.Len(t, sub.ID, 26)
+		created = append(created, sub.ID)
+	}
+	assert.True(t, slices.IsSorted(created), "ULIDs are time-ordered")
+
+	got, err := repo.GetByID(ctx, created[0])
+	require.NoError(t, err)
+	assert.Equal(t, created[0], got.ID)
+
+	page, next, err := repo.Paginate(ctx, userID, nil, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	cursor, err := DecodeCursor(next)
+	require.NoError(t, err)
+	rest, _, err := repo.Paginate(ctx, userID, &cursor, 2)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Len(t, rest[0].ID, 26)
+}
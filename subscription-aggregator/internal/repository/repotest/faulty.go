@@ -0,0 +1,211 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+
+	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
+	"subscription-aggregator/internal/repository"
+)
+
+// FaultyRepo wraps a SubscriptionRepository and returns configured errors for
+// selected methods, delegating everything else to the wrapped repository.
+type FaultyRepo struct {
+	repository.SubscriptionRepository
+
+	mu     sync.Mutex
+	faults map[string]error
+}
+
+func NewFaultyRepo(inner repository.SubscriptionRepository) *FaultyRepo {
+	return &FaultyRepo{SubscriptionRepository: inner, faults: make(map[string]error)}
+}
+
+func (f *FaultyRepo) FailOn(method string, err error) *FaultyRepo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults[method] = err
+	return f
+}
+
+func (f *FaultyRepo) fault(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.faults[method]
+}
+
+func (f *FaultyRepo) Create(ctx context.Context, sub *model.Subscription) error {
+	if err := f.fault("Create"); err != nil {
+		return err
+	}
+	return f.SubscriptionRepository.Create(ctx, sub)
+}
+
+func (f *FaultyRepo) GetByID(ctx context.Context, id string) (*model.Subscription, error) {
+	if err := f.fault("GetByID"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.GetByID(ctx, id)
+}
+
+func (f *FaultyRepo) RecordAccess(ctx context.Context, id string) error {
+	if err := f.fault("RecordAccess"); err != nil {
+		return err
+	}
+	return f.SubscriptionRepository.RecordAccess(ctx, id)
+}
+
+func (f *FaultyRepo) ListByUserID(ctx context.Context, userID string, opts repository.ListOptions) ([]model.Subscription, error) {
+	if err := f.fault("ListByUserID"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.ListByUserID(ctx, userID, opts)
+}
+
+func (f *FaultyRepo) Paginate(ctx context.Context, userID string, after *repository.Cursor, limit int) ([]model.Subscription, string, error) {
+	if err := f.fault("Paginate"); err != nil {
+		return nil, "", err
+	}
+	return f.SubscriptionRepository.Paginate(ctx, userID, after, limit)
+}
+
+func (f *FaultyRepo) CountByUserID(ctx context.Context, userID string, opts repository.ListOptions) (int, error) {
+	if err := f.fault("CountByUserID"); err != nil {
+		return 0, err
+	}
+	return f.SubscriptionRepository.CountByUserID(ctx, userID, opts)
+}
+
+func (f *FaultyRepo) Counts(ctx context.Context, userID string, now monthdate.MonthDate) (model.SubscriptionCounts, error) {
+	if err := f.fault("Counts"); err != nil {
+		return model.SubscriptionCounts{}, err
+	}
+	return f.SubscriptionRepository.Counts(ctx, userID, now)
+}
+
+func (f *FaultyRepo) ListStale(ctx context.Context, userID string, days int) ([]model.Subscription, error) {
+	if err := f.fault("ListStale"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.ListStale(ctx, userID, days)
+}
+
+func (f *FaultyRepo) ListExpiring(ctx context.Context, userID string, from, to monthdate.MonthDate) ([]model.Subscription, error) {
+	if err := f.fault("ListExpiring"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.ListExpiring(ctx, userID, from, to)
+}
+
+func (f *FaultyRepo) ListActiveAt(ctx context.Context, userID, month string) ([]model.Subscription, error) {
+	if err := f.fault("ListActiveAt"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.ListActiveAt(ctx, userID, month)
+}
+
+func (f *FaultyRepo) GetUserDashboard(ctx context.Context, userID, from, to string) (*model.UserDashboard, error) {
+	if err := f.fault("GetUserDashboard"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.GetUserDashboard(ctx, userID, from, to)
+}
+
+func (f *FaultyRepo) Update(ctx context.Context, id string, sub *model.Subscription) error {
+	if err := f.fault("Update"); err != nil {
+		return err
+	}
+	return f.SubscriptionRepository.Update(ctx, id, sub)
+}
+
+func (f *FaultyRepo) Patch(ctx context.Context, id string, fields map[string]any) (*model.Subscription, error) {
+	if err := f.fault("Patch"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.Patch(ctx, id, fields)
+}
+
+func (f *FaultyRepo) Upsert(ctx context.Context, id string, sub *model.Subscription) (bool, error) {
+	if err := f.fault("Upsert"); err != nil {
+		return false, err
+	}
+	return f.SubscriptionRepository.Upsert(ctx, id, sub)
+}
+
+func (f *FaultyRepo) UpsertByKey(ctx context.Context, sub *model.Subscription) (bool, error) {
+	if err := f.fault("UpsertByKey"); err != nil {
+		return false, err
+	}
+	return f.SubscriptionRepository.UpsertByKey(ctx, sub)
+}
+
+func (f *FaultyRepo) BulkInsert(ctx context.Context, subs []*model.Subscription) error {
+	if err := f.fault("BulkInsert"); err != nil {
+		return err
+	}
+	return f.SubscriptionRepository.BulkInsert(ctx, subs)
+}
+
+func (f *FaultyRepo) Delete(ctx context.Context, id string) error {
+	if err := f.fault("Delete"); err != nil {
+		return err
+	}
+	return f.SubscriptionRepository.Delete(ctx, id)
+}
+
+func (f *FaultyRepo) Restore(ctx context.Context, id string) error {
+	if err := f.fault("Restore"); err != nil {
+		return err
+	}
+	return f.SubscriptionRepository.Restore(ctx, id)
+}
+
+func (f *FaultyRepo) TotalCost(ctx context.Context, filter repository.CostFilter) (model.CostSummary, error) {
+	if err := f.fault("TotalCost"); err != nil {
+		return model.CostSummary{}, err
+	}
+	return f.SubscriptionRepository.TotalCost(ctx, filter)
+}
+
+func (f *FaultyRepo) FindDuplicates(ctx context.Context, excludeID string, sub *model.Subscription) ([]model.Duplicate, error) {
+	if err := f.fault("FindDuplicates"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.FindDuplicates(ctx, excludeID, sub)
+}
+
+func (f *FaultyRepo) ServiceUsage(ctx context.Context, limit, offset int) ([]model.ServiceUsage, int, error) {
+	if err := f.fault("ServiceUsage"); err != nil {
+		return nil, 0, err
+	}
+	return f.SubscriptionRepository.ServiceUsage(ctx, limit, offset)
+}
+
+func (f *FaultyRepo) PriceAnomalies(ctx context.Context, userID string, multiplier float64) ([]model.PriceAnomaly, error) {
+	if err := f.fault("PriceAnomalies"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.PriceAnomalies(ctx, userID, multiplier)
+}
+
+func (f *FaultyRepo) RecordIncident(ctx context.Context, id string) (*model.Subscription, error) {
+	if err := f.fault("RecordIncident"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.RecordIncident(ctx, id)
+}
+
+func (f *FaultyRepo) ListSLABreaches(ctx context.Context, userID string, minIncidents int) ([]model.Subscription, error) {
+	if err := f.fault("ListSLABreaches"); err != nil {
+		return nil, err
+	}
+	return f.SubscriptionRepository.ListSLABreaches(ctx, userID, minIncidents)
+}
+
+func (f *FaultyRepo) Retention(ctx context.Context, cohort monthdate.MonthDate, months int) (model.Retention, error) {
+	if err := f.fault("Retention"); err != nil {
+		return model.Retention{}, err
+	}
+	return f.SubscriptionRepository.Retention(ctx, cohort, months)
+}
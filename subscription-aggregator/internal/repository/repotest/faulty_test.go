@@ -0,0 +1,40 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"subscription-aggregator/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFaultyRepo_WrapsEveryMethod calls every interface method with a fault
+// configured and no inner repository. A method without its own wrapper falls
+// through to the nil embedded interface and panics instead of returning the
+// fault.
+func TestFaultyRepo_WrapsEveryMethod(t *testing.T) {
+	errFault := errors.New("injected fault")
+	iface := reflect.TypeOf((*repository.SubscriptionRepository)(nil)).Elem()
+
+	for i := 0; i < iface.NumMethod(); i++ {
+		name := iface.Method(i).Name
+		t.Run(name, func(t *testing.T) {
+			method := reflect.ValueOf(NewFaultyRepo(nil).FailOn(name, errFault)).MethodByName(name)
+			args := make([]reflect.Value, method.Type().NumIn())
+			args[0] = reflect.ValueOf(context.Background())
+			for j := 1; j < len(args); j++ {
+				args[j] = reflect.Zero(method.Type().In(j))
+			}
+
+			var out []reflect.Value
+			if !assert.NotPanics(t, func() { out = method.Call(args) }, "FaultyRepo has no %s wrapper", name) {
+				return
+			}
+			err, _ := out[len(out)-1].Interface().(error)
+			assert.ErrorIs(t, err, errFault)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrShareLinkNotFound = errors.New("share link not found")
+
+type ShareLinkRepository interface {
+	Create(ctx context.Context, tokenHash, subscriptionID string, expiresAt time.Time) error
+	// SubscriptionID resolves an unexpired link to the subscription it shares.
+	SubscriptionID(ctx context.Context, tokenHash string, now time.Time) (string, error)
+}
+
+type PostgresShareLinkRepo struct {
+	conn DBTX
+	repoOptions
+}
+
+// NewPostgresShareLinkRepo reads and writes subscription ids in the scheme
+// chosen by WithIDScheme.
+func NewPostgresShareLinkRepo(conn DBTX, opts ...RepoOption) *PostgresShareLinkRepo {
+	return &PostgresShareLinkRepo{conn: conn, repoOptions: newRepoOptions(opts)}
+}
+
+func (r *PostgresShareLinkRepo) Create(ctx context.Context, tokenHash, subscriptionID string, expiresAt time.Time) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := r.idScheme.Parse(subscriptionID)
+	if err != nil {
+		return fmt.Errorf("invalid subscription ID: %w", err)
+	}
+
+	query := `
+		INSERT INTO share_links (token_hash, subscription_id, expires_at)
+		VALUES ($1, $2, $3)`
+
+	if _, err := txOrConn(ctx, r.conn).Exec(ctx, query, tokenHash, parsedID, expiresAt); err != nil {
+		slog.Error("Failed to create share link", "subscription_id", subscriptionID, "error", err)
+		return fmt.Errorf("database insert failed: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresShareLinkRepo) SubscriptionID(ctx context.Context, tokenHash string, now time.Time) (string, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT subscription_id
+		FROM share_links
+		WHERE token_hash = $1 AND expires_at > $2`
+
+	var id uuid.UUID
+	if err := txOrConn(ctx, r.conn).QueryRow(ctx, query, tokenHash, now).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrShareLinkNotFound
+		}
+		slog.Error("Failed to resolve share link", "error", err)
+		return "", fmt.Errorf("database query failed: %w", err)
+	}
+	return r.idScheme.Format(id), nil
+}
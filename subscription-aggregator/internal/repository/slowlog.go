@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+const maxLoggedQueryLen = 200
+
+// SlowQueryConn wraps a DBTX and logs a warning for every statement that
+// takes longer than the threshold. Query time includes reading all rows.
+// Argument values are never logged, since they carry user data; only their
+// count is.
+type SlowQueryConn struct {
+	conn      DBTX
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+func NewSlowQueryConn(conn DBTX, threshold time.Duration) *SlowQueryConn {
+	return &SlowQueryConn{conn: conn, threshold: threshold, logger: slog.Default()}
+}
+
+func (c *SlowQueryConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	start := time.Now()
+	tag, err := c.conn.Exec(ctx, sql, args...)
+	c.observe(ctx, sql, args, start)
+	return tag, err
+}
+
+func (c *SlowQueryConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	start := time.Now()
+	rows, err := c.conn.Query(ctx, sql, args...)
+	if err != nil {
+		c.observe(ctx, sql, args, start)
+		return rows, err
+	}
+	return &timedRows{Rows: rows, done: func() { c.observe(ctx, sql, args, start) }}, nil
+}
+
+func (c *SlowQueryConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	start := time.Now()
+	row := c.conn.QueryRow(ctx, sql, args...)
+	return &timedRow{Row: row, done: func() { c.observe(ctx, sql, args, start) }}
+}
+
+// SendBatch times the whole batch, from sending until its results are closed.
+func (c *SlowQueryConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	start := time.Now()
+	results := c.conn.SendBatch(ctx, b)
+	sql := fmt.Sprintf("batch of %d queries", b.Len())
+	return &timedBatch{BatchResults: results, done: func() { c.observe(ctx, sql, nil, start) }}
+}
+
+func (c *SlowQueryConn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	start := time.Now()
+	n, err := c.conn.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	c.observe(ctx, "COPY "+tableName.Sanitize(), nil, start)
+	return n, err
+}
+
+func (c *SlowQueryConn) observe(ctx context.Context, sql string, args []any, start time.Time) {
+	elapsed := time.Since(start)
+	if elapsed < c.threshold {
+		return
+	}
+	c.logger.WarnContext(ctx, "Slow query",
+		"operation", compactQuery(sql),
+		"duration_ms", elapsed.Milliseconds(),
+		"arg_count", len(args),
+	)
+}
+
+func compactQuery(sql string) string {
+	q := strings.Join(strings.Fields(sql), " ")
+	if len(q) > maxLoggedQueryLen {
+		q = q[:maxLoggedQueryLen] + "..."
+	}
+	return q
+}
+
+type timedRows struct {
+	pgx.Rows
+	done   func()
+	closed bool
+}
+
+func (r *timedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *timedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *timedRows) finish() {
+	if !r.closed {
+		r.closed = true
+		r.done()
+	}
+}
+
+type timedBatch struct {
+	pgx.BatchResults
+	done func()
+}
+
+func (b *timedBatch) Close() error {
+	err := b.BatchResults.Close()
+	b.done()
+	return err
+}
+
+type timedRow struct {
+	pgx.Row
+	done func()
+}
+
+func (r *timedRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.done()
+	return err
+}
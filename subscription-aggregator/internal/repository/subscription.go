@@ -3,13 +3,59 @@ package repository
 import (
 	"context"
 	"subscription-aggregator/internal/model"
+	"subscription-aggregator/internal/monthdate"
 )
 
+type ListOptions struct {
+	ServiceName   string
+	CaseSensitive bool
+	// Metadata matches top-level metadata keys against their text value.
+	Metadata map[string]string
+	// ActiveOn, an MM-YYYY month, keeps only subscriptions running during it.
+	ActiveOn string
+	// IncludeDeleted also lists soft-deleted subscriptions.
+	IncludeDeleted bool
+	// Sort is one of ListSorts; empty means DefaultListSort.
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+type CostFilter struct {
+	UserID         string
+	ServiceName    string
+	ExcludeService string
+	CaseSensitive  bool
+	From           string
+	To             string
+	// Itemize also returns each matching subscription's contribution.
+	Itemize bool
+}
+
 type SubscriptionRepository interface {
 	Create(ctx context.Context, sub *model.Subscription) error
 	GetByID(ctx context.Context, id string) (*model.Subscription, error)
-	ListByUserID(ctx context.Context, userID string) ([]model.Subscription, error)
+	RecordAccess(ctx context.Context, id string) error
+	ListByUserID(ctx context.Context, userID string, opts ListOptions) ([]model.Subscription, error)
+	Paginate(ctx context.Context, userID string, after *Cursor, limit int) (subs []model.Subscription, nextCursor string, err error)
+	CountByUserID(ctx context.Context, userID string, opts ListOptions) (int, error)
+	Counts(ctx context.Context, userID string, now monthdate.MonthDate) (model.SubscriptionCounts, error)
+	ListStale(ctx context.Context, userID string, days int) ([]model.Subscription, error)
+	ListExpiring(ctx context.Context, userID string, from, to monthdate.MonthDate) ([]model.Subscription, error)
+	ListActiveAt(ctx context.Context, userID, month string) ([]model.Subscription, error)
 	Update(ctx context.Context, id string, sub *model.Subscription) error
+	Patch(ctx context.Context, id string, fields map[string]any) (*model.Subscription, error)
+	Upsert(ctx context.Context, id string, sub *model.Subscription) (created bool, err error)
+	UpsertByKey(ctx context.Context, sub *model.Subscription) (created bool, err error)
+	BulkInsert(ctx context.Context, subs []*model.Subscription) error
 	Delete(ctx context.Context, id string) error
-	TotalCost(ctx context.Context, userID, serviceName, from, to string) (int, error)
+	Restore(ctx context.Context, id string) error
+	TotalCost(ctx context.Context, f CostFilter) (model.CostSummary, error)
+	GetUserDashboard(ctx context.Context, userID, from, to string) (*model.UserDashboard, error)
+	FindDuplicates(ctx context.Context, excludeID string, sub *model.Subscription) ([]model.Duplicate, error)
+	ServiceUsage(ctx context.Context, limit, offset int) (usage []model.ServiceUsage, total int, err error)
+	PriceAnomalies(ctx context.Context, userID string, multiplier float64) ([]model.PriceAnomaly, error)
+	RecordIncident(ctx context.Context, id string) (*model.Subscription, error)
+	ListSLABreaches(ctx context.Context, userID string, minIncidents int) ([]model.Subscription, error)
+	Retention(ctx context.Context, cohort monthdate.MonthDate, months int) (model.Retention, error)
 }
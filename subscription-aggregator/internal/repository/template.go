@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"subscription-aggregator/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+type TemplateRepository interface {
+	Search(ctx context.Context, query string, limit int) ([]model.Template, error)
+	GetByServiceName(ctx context.Context, serviceName string) (*model.Template, error)
+}
+
+type PostgresTemplateRepo struct {
+	conn DBTX
+	repoOptions
+}
+
+func NewPostgresTemplateRepo(conn DBTX, opts ...RepoOption) *PostgresTemplateRepo {
+	return &PostgresTemplateRepo{conn: conn, repoOptions: newRepoOptions(opts)}
+}
+
+func (r *PostgresTemplateRepo) Search(ctx context.Context, search string, limit int) ([]model.Template, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT service_name, default_price, default_billing_cycle, category, logo_url
+		FROM subscription_templates
+		WHERE $1 = '' OR strpos(lower(service_name), lower($1)) > 0
+		ORDER BY strpos(lower(service_name), lower($1)), service_name
+		LIMIT $2`
+
+	rows, err := txOrConn(ctx, r.conn).Query(ctx, query, search, limit)
+	if err != nil {
+		slog.Error("Failed to search templates", "search", search, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make([]model.Template, 0)
+	for rows.Next() {
+		var t model.Template
+		if err := rows.Scan(&t.ServiceName, &t.DefaultPrice, &t.DefaultBillingCycle, &t.Category, &t.LogoURL); err != nil {
+			slog.Error("Failed to scan template row", "error", err)
+			continue
+		}
+		templates = append(templates, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *PostgresTemplateRepo) GetByServiceName(ctx context.Context, serviceName string) (*model.Template, error) {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT service_name, default_price, default_billing_cycle, category, logo_url
+		FROM subscription_templates
+		WHERE service_name = $1`
+
+	var t model.Template
+	err := txOrConn(ctx, r.conn).QueryRow(ctx, query, serviceName).Scan(
+		&t.ServiceName,
+		&t.DefaultPrice,
+		&t.DefaultBillingCycle,
+		&t.Category,
+		&t.LogoURL,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		slog.Error("Failed to get template", "service_name", serviceName, "error", err)
+		return nil, fmt.Errorf("database query failed: %w", err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type txKey struct{}
+
+// WithTx returns a context carrying tx. Repository methods called with it run
+// inside that transaction instead of on their own connection.
+func WithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored by WithTx, or nil.
+func TxFromContext(ctx context.Context) pgx.Tx {
+	tx, _ := ctx.Value(txKey{}).(pgx.Tx)
+	return tx
+}
+
+func txOrConn(ctx context.Context, conn DBTX) DBTX {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return conn
+}
+
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// InTx runs fn in a transaction carried by its context, committing when fn
+// succeeds and rolling back otherwise. If ctx already holds a transaction, fn
+// joins it and the outer caller decides the outcome.
+func InTx(ctx context.Context, db TxBeginner, fn func(ctx context.Context) error) error {
+	if TxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	if err := fn(WithTx(ctx, tx)); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return errors.Join(err, fmt.Errorf("rollback transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// ErrUserNotFound is returned for a missing user, including a subscription
+// write whose user_id does not reference one.
+var ErrUserNotFound = errors.New("user not found")
+
+var ErrUserExists = errors.New("user already exists")
+
+type UserRepository interface {
+	Create(ctx context.Context, id string) error
+	// Delete removes the user and, through the foreign key, their subscriptions.
+	Delete(ctx context.Context, id string) error
+}
+
+type PostgresUserRepo struct {
+	conn DBTX
+	repoOptions
+}
+
+func NewPostgresUserRepo(conn DBTX, opts ...RepoOption) *PostgresUserRepo {
+	return &PostgresUserRepo{conn: conn, repoOptions: newRepoOptions(opts)}
+}
+
+func (r *PostgresUserRepo) Create(ctx context.Context, id string) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	if _, err := txOrConn(ctx, r.conn).Exec(ctx, `INSERT INTO users (id) VALUES ($1)`, parsedID); err != nil {
+		if isUniqueViolation(err) {
+			return ErrUserExists
+		}
+		slog.Error("Failed to create user", "id", id, "error", err)
+		return fmt.Errorf("database insert failed: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresUserRepo) Delete(ctx context.Context, id string) error {
+	ctx, cancel := QueryContext(ctx, r.queryTimeout)
+	defer cancel()
+
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	tag, err := txOrConn(ctx, r.conn).Exec(ctx, `DELETE FROM users WHERE id = $1`, parsedID)
+	if err != nil {
+		slog.Error("Failed to delete user", "id", id, "error", err)
+		return fmt.Errorf("database delete failed: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
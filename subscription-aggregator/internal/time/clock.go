@@ -0,0 +1,47 @@
+// Package time provides a swappable clock so time-dependent handlers can be
+// tested deterministically. Import it as apptime to avoid clashing with the
+// standard library.
+package time
+
+import (
+	"sync"
+	stdtime "time"
+)
+
+type ClockSource interface {
+	Now() stdtime.Time
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() stdtime.Time {
+	return stdtime.Now()
+}
+
+// FakeClock returns a fixed instant until it is moved with Set or Advance.
+type FakeClock struct {
+	mu  sync.Mutex
+	now stdtime.Time
+}
+
+func NewFakeClock(now stdtime.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() stdtime.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Set(now stdtime.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func (c *FakeClock) Advance(d stdtime.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
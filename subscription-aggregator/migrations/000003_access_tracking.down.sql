@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS last_accessed_at,
+    DROP COLUMN IF EXISTS created_at;
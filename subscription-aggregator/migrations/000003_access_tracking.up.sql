@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMPTZ;
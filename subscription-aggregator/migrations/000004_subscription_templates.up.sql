@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS subscription_templates (
+    service_name TEXT PRIMARY KEY,
+    default_price INTEGER NOT NULL CHECK (default_price > 0),
+    default_billing_cycle TEXT NOT NULL DEFAULT 'monthly',
+    category TEXT NOT NULL,
+    logo_url TEXT
+);
+
+INSERT INTO subscription_templates (service_name, default_price, default_billing_cycle, category, logo_url) VALUES
+    ('Spotify', 299, 'monthly', 'music', 'https://logo.clearbit.com/spotify.com'),
+    ('Apple Music', 169, 'monthly', 'music', 'https://logo.clearbit.com/apple.com'),
+    ('YouTube Premium', 299, 'monthly', 'video', 'https://logo.clearbit.com/youtube.com'),
+    ('Netflix', 799, 'monthly', 'video', 'https://logo.clearbit.com/netflix.com'),
+    ('Disney+', 699, 'monthly', 'video', 'https://logo.clearbit.com/disneyplus.com'),
+    ('Amazon Prime', 599, 'monthly', 'video', 'https://logo.clearbit.com/amazon.com'),
+    ('Yandex Plus', 399, 'monthly', 'bundle', 'https://logo.clearbit.com/yandex.ru'),
+    ('Kinopoisk', 299, 'monthly', 'video', 'https://logo.clearbit.com/kinopoisk.ru'),
+    ('Okko', 399, 'monthly', 'video', 'https://logo.clearbit.com/okko.tv'),
+    ('ivi', 399, 'monthly', 'video', 'https://logo.clearbit.com/ivi.ru'),
+    ('VK Music', 199, 'monthly', 'music', 'https://logo.clearbit.com/vk.com'),
+    ('iCloud+', 149, 'monthly', 'storage', 'https://logo.clearbit.com/icloud.com'),
+    ('Google One', 139, 'monthly', 'storage', 'https://logo.clearbit.com/one.google.com'),
+    ('Dropbox', 999, 'monthly', 'storage', 'https://logo.clearbit.com/dropbox.com'),
+    ('Microsoft 365', 699, 'monthly', 'productivity', 'https://logo.clearbit.com/microsoft.com'),
+    ('Notion', 799, 'monthly', 'productivity', 'https://logo.clearbit.com/notion.so'),
+    ('Adobe Creative Cloud', 4999, 'monthly', 'productivity', 'https://logo.clearbit.com/adobe.com'),
+    ('ChatGPT Plus', 1999, 'monthly', 'productivity', 'https://logo.clearbit.com/openai.com'),
+    ('Xbox Game Pass', 899, 'monthly', 'gaming', 'https://logo.clearbit.com/xbox.com'),
+    ('PlayStation Plus', 799, 'monthly', 'gaming', 'https://logo.clearbit.com/playstation.com')
+ON CONFLICT (service_name) DO NOTHING;
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS subscriptions_user_service_start_key;
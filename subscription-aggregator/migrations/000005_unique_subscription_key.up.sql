@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_service_start_key UNIQUE (user_id, service_name, start_date);
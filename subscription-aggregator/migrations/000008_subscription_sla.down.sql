@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS incident_count,
+    DROP COLUMN IF EXISTS last_incident_at,
+    DROP COLUMN IF EXISTS sla_uptime_pct;
@@ -0,0 +1,4 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS sla_uptime_pct NUMERIC(5,2) CHECK (sla_uptime_pct BETWEEN 0 AND 100),
+    ADD COLUMN IF NOT EXISTS last_incident_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS incident_count INTEGER NOT NULL DEFAULT 0;
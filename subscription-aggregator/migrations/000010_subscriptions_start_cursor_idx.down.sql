@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS subscriptions_user_start_id_idx;
+CREATE INDEX IF NOT EXISTS subscriptions_user_id_id_idx ON subscriptions (user_id, id);
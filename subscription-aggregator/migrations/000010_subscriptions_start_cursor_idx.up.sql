@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS subscriptions_user_id_id_idx;
+CREATE INDEX IF NOT EXISTS subscriptions_user_start_id_idx
+    ON subscriptions (user_id, (right(start_date, 4) || left(start_date, 2)), id);
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_user_id_fkey;
+DROP TABLE IF EXISTS users;
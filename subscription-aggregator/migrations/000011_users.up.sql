@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Existing subscriptions keep working: their owners become users.
+INSERT INTO users (id)
+SELECT DISTINCT user_id FROM subscriptions
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS subscriptions_user_service_start_key;
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_service_start_key UNIQUE (user_id, service_name, start_date);
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Deleted rows must not block re-creating the same subscription.
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS subscriptions_user_service_start_key;
+CREATE UNIQUE INDEX IF NOT EXISTS subscriptions_user_service_start_key
+    ON subscriptions (user_id, service_name, start_date) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- created_at predates this migration (000003) and stays.
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS updated_at;
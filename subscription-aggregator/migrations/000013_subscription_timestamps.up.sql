@@ -0,0 +1,6 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Existing rows have not changed since they were created.
+UPDATE subscriptions SET updated_at = created_at;
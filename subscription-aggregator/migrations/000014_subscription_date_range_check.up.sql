@@ -0,0 +1,10 @@
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_date_range_check;
+-- Compares MM-YYYY text as YYYYMM, like the start_date sort key. Deleted rows
+-- are exempt, so restoring one re-checks it. Existing rows may violate the
+-- check, as PATCH used to accept inverted ranges, so it is only validated in
+-- 000016 once those rows are quarantined.
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_date_range_check
+    CHECK (deleted_at IS NOT NULL OR end_date IS NULL
+           OR (right(end_date, 4) || left(end_date, 2)) >= (right(start_date, 4) || left(start_date, 2)))
+    NOT VALID;
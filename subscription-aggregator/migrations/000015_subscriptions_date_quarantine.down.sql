@@ -0,0 +1 @@
+DROP TABLE IF EXISTS subscriptions_date_quarantine;
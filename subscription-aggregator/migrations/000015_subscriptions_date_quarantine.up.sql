@@ -0,0 +1,25 @@
+-- check-dates used to create this table at runtime as LIKE subscriptions, so
+-- it may exist without the columns subscriptions gained since.
+CREATE TABLE IF NOT EXISTS subscriptions_date_quarantine (
+    id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL,
+    user_id UUID NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT,
+    reason TEXT NOT NULL,
+    quarantined_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE subscriptions_date_quarantine
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS metadata JSONB,
+    ADD COLUMN IF NOT EXISTS sla_uptime_pct NUMERIC(5,2),
+    ADD COLUMN IF NOT EXISTS last_incident_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS incident_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS subscriptions_date_quarantine_id_key
+    ON subscriptions_date_quarantine (id);
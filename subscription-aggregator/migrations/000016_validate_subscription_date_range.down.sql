@@ -0,0 +1,7 @@
+-- Quarantined rows stay soft-deleted; the constraint itself belongs to 000014.
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_date_range_check;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_date_range_check
+    CHECK (deleted_at IS NOT NULL OR end_date IS NULL
+           OR (right(end_date, 4) || left(end_date, 2)) >= (right(start_date, 4) || left(start_date, 2)))
+    NOT VALID;
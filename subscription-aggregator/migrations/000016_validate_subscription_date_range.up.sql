@@ -0,0 +1,24 @@
+-- Databases that ran an earlier 000014 have the check without the deleted
+-- row exemption.
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_date_range_check;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_date_range_check
+    CHECK (deleted_at IS NOT NULL OR end_date IS NULL
+           OR (right(end_date, 4) || left(end_date, 2)) >= (right(start_date, 4) || left(start_date, 2)))
+    NOT VALID;
+
+INSERT INTO subscriptions_date_quarantine (id, service_name, price, user_id, start_date, end_date, created_at,
+    last_accessed_at, metadata, sla_uptime_pct, last_incident_at, incident_count, deleted_at, updated_at, reason)
+SELECT id, service_name, price, user_id, start_date, end_date, created_at,
+    last_accessed_at, metadata, sla_uptime_pct, last_incident_at, incident_count, deleted_at, updated_at,
+    'end_date before start_date'
+FROM subscriptions
+WHERE deleted_at IS NULL AND end_date IS NOT NULL
+  AND (right(end_date, 4) || left(end_date, 2)) < (right(start_date, 4) || left(start_date, 2))
+ON CONFLICT (id) DO NOTHING;
+
+UPDATE subscriptions SET deleted_at = NOW(), updated_at = NOW()
+WHERE deleted_at IS NULL AND end_date IS NOT NULL
+  AND (right(end_date, 4) || left(end_date, 2)) < (right(start_date, 4) || left(start_date, 2));
+
+ALTER TABLE subscriptions VALIDATE CONSTRAINT subscriptions_date_range_check;
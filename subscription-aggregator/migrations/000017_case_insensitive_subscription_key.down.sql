@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS subscriptions_user_service_start_key;
+CREATE UNIQUE INDEX subscriptions_user_service_start_key
+    ON subscriptions (user_id, service_name, start_date) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+-- service_name filters match case-insensitively by default, so the unique key
+-- does too: "Spotify" and "spotify" starting the same month are one
+-- subscription. Existing case variants keep the most recently updated row
+-- and soft-delete the others, so nothing is lost.
+UPDATE subscriptions s SET deleted_at = NOW(), updated_at = NOW()
+WHERE s.deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM subscriptions o
+      WHERE o.deleted_at IS NULL
+        AND o.user_id = s.user_id
+        AND lower(o.service_name) = lower(s.service_name)
+        AND o.start_date = s.start_date
+        AND (o.updated_at, o.id) > (s.updated_at, s.id));
+
+DROP INDEX IF EXISTS subscriptions_user_service_start_key;
+CREATE UNIQUE INDEX subscriptions_user_service_start_key
+    ON subscriptions (user_id, lower(service_name), start_date) WHERE deleted_at IS NULL;